@@ -0,0 +1,188 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// cookieJar is an http.CookieJar over a collection's stored cookies. Unlike
+// net/http/cookiejar it can list its contents, so they can be saved and
+// shown on the cookies page.
+type cookieJar struct {
+	mu      sync.Mutex
+	cookies []Cookie
+	changed bool
+}
+
+func newCookieJar(cookies []Cookie) *cookieJar {
+	return &cookieJar{cookies: append([]Cookie(nil), cookies...)}
+}
+
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := strings.ToLower(u.Hostname())
+	now := time.Now()
+	for _, c := range cookies {
+		cookie := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.TrimPrefix(strings.ToLower(c.Domain), "."),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}
+		if cookie.Domain == "" {
+			cookie.Domain = host
+			cookie.HostOnly = true
+		} else if !cookieDomainAllowed(host, cookie.Domain) {
+			continue
+		} else if cookie.Domain == host && (net.ParseIP(host) != nil || isPublicSuffix(host)) {
+			cookie.HostOnly = true
+		}
+		if cookie.Path == "" || !strings.HasPrefix(cookie.Path, "/") {
+			cookie.Path = defaultCookiePath(u.Path)
+		}
+
+		expired := false
+		switch {
+		case c.MaxAge < 0:
+			expired = true
+		case c.MaxAge > 0:
+			cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second).Unix()
+		case !c.Expires.IsZero():
+			cookie.Expires = c.Expires.Unix()
+			expired = !c.Expires.After(now)
+		}
+
+		i := j.find(cookie)
+		switch {
+		case expired && i >= 0:
+			j.cookies = append(j.cookies[:i], j.cookies[i+1:]...)
+		case expired:
+			continue
+		case i >= 0:
+			j.cookies[i] = cookie
+		default:
+			j.cookies = append(j.cookies, cookie)
+		}
+		j.changed = true
+	}
+}
+
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	now := time.Now().Unix()
+
+	var matched []Cookie
+	for _, cookie := range j.cookies {
+		if cookie.Expires != 0 && cookie.Expires <= now {
+			continue
+		}
+		if cookie.Secure && u.Scheme != "https" {
+			continue
+		}
+		if cookie.HostOnly && host != cookie.Domain || !cookie.HostOnly && !domainMatches(host, cookie.Domain) {
+			continue
+		}
+		if !pathMatches(path, cookie.Path) {
+			continue
+		}
+		matched = append(matched, cookie)
+	}
+	sort.SliceStable(matched, func(a, b int) bool {
+		return len(matched[a].Path) > len(matched[b].Path)
+	})
+
+	result := make([]*http.Cookie, len(matched))
+	for i, cookie := range matched {
+		result[i] = &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+	}
+	return result
+}
+
+// contents returns the unexpired cookies and whether any response changed
+// the jar.
+func (j *cookieJar) contents() ([]Cookie, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().Unix()
+	var cookies []Cookie
+	for _, cookie := range j.cookies {
+		if cookie.Expires == 0 || cookie.Expires > now {
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies, j.changed
+}
+
+func (j *cookieJar) find(cookie Cookie) int {
+	for i, existing := range j.cookies {
+		if existing.Name == cookie.Name && existing.Domain == cookie.Domain && existing.Path == cookie.Path {
+			return i
+		}
+	}
+	return -1
+}
+
+// cookieDomainAllowed reports whether a response from host may set a cookie
+// for domain. Like browsers it refuses public suffixes such as "com" or
+// "co.uk", except on the suffix's own host, and domains on IP addresses.
+func cookieDomainAllowed(host string, domain string) bool {
+	if host == domain {
+		return true
+	}
+	if net.ParseIP(host) != nil || isPublicSuffix(domain) {
+		return false
+	}
+	return domainMatches(host, domain)
+}
+
+func isPublicSuffix(domain string) bool {
+	return publicsuffix.List.PublicSuffix(domain) == domain
+}
+
+func domainMatches(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func pathMatches(requestPath string, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+func defaultCookiePath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+// cookieExpiry describes when a cookie expires for the cookies page.
+func cookieExpiry(cookie Cookie) string {
+	if cookie.Expires == 0 {
+		return "session"
+	}
+	return time.Unix(cookie.Expires, 0).Format("2006-01-02 15:04")
+}
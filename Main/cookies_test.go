@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCookieJarDomains(t *testing.T) {
+	tests := []struct {
+		url      string
+		domain   string
+		stored   bool
+		hostOnly bool
+	}{
+		{"https://api.example.com/", "", true, true},
+		{"https://api.example.com/", "example.com", true, false},
+		{"https://api.example.com/", ".example.com", true, false},
+		{"https://api.example.com/", "other.com", false, false},
+		{"https://api.example.com/", "com", false, false},
+		{"https://api.example.co.uk/", "co.uk", false, false},
+		{"https://api.example.co.uk/", "example.co.uk", true, false},
+		{"https://foo.github.io/", "github.io", false, false},
+		{"http://localhost:8080/", "localhost", true, true},
+		{"http://127.0.0.1/", "127.0.0.1", true, true},
+		{"http://127.0.0.1/", "0.0.1", false, false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		jar := newCookieJar(nil)
+		jar.SetCookies(u, []*http.Cookie{{Name: "id", Value: "1", Domain: tt.domain}})
+		cookies, _ := jar.contents()
+		if stored := len(cookies) == 1; stored != tt.stored {
+			t.Errorf("%s with Domain=%q: stored = %v, want %v", tt.url, tt.domain, stored, tt.stored)
+			continue
+		}
+		if tt.stored && cookies[0].HostOnly != tt.hostOnly {
+			t.Errorf("%s with Domain=%q: HostOnly = %v, want %v", tt.url, tt.domain, cookies[0].HostOnly, tt.hostOnly)
+		}
+	}
+}
+
+func TestCookiesKeptOutOfWorkspace(t *testing.T) {
+	cookies := []Cookie{{Name: "session", Value: "secret", Domain: "example.com", Path: "/"}}
+	storage := Storage{Collections: []Collection{{ID: "c-pets", Name: "Pets", Requests: []Api{}, Folders: []Folder{}, LocalVariables: []LocalVariable{}, Cookies: cookies}}}
+
+	t.Run("file", func(t *testing.T) {
+		path := useDataFile(t, "v4.json")
+		must(t, WriteFile(storage))
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "secret") {
+			t.Errorf("data file holds the cookie: %s", data)
+		}
+		ignore, _ := os.ReadFile(filepath.Join(filepath.Dir(path), ".gitignore"))
+		if want := "/" + filepath.Base(localStatePath()) + "\n"; string(ignore) != want {
+			t.Errorf(".gitignore = %q, want %q", ignore, want)
+		}
+		loaded, err := ReadFile()
+		must(t, err)
+		if got := loaded.Collections[0].Cookies; len(got) != 1 || got[0].Value != "secret" {
+			t.Errorf("cookies after reload = %+v", got)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		oldFile, oldDir := fileName, dataDir
+		dataDir = t.TempDir()
+		t.Cleanup(func() { fileName, dataDir = oldFile, oldDir })
+
+		must(t, WriteFile(storage))
+		meta, _ := os.ReadFile(filepath.Join(dataDir, "pets", collectionFileName))
+		if strings.Contains(string(meta), "secret") {
+			t.Errorf("collection.json holds the cookie: %s", meta)
+		}
+		ignore, _ := os.ReadFile(filepath.Join(dataDir, ".gitignore"))
+		if !strings.Contains(string(ignore), localStateFileName) {
+			t.Errorf(".gitignore = %q", ignore)
+		}
+		loaded, err := ReadFile()
+		must(t, err)
+		if got := loaded.Collections[0].Cookies; len(got) != 1 || got[0].Value != "secret" {
+			t.Errorf("cookies after reload = %+v", got)
+		}
+	})
+
+	t.Run("moves tracked cookies out", func(t *testing.T) {
+		path := useDataFile(t, "v4.json")
+		data, _ := os.ReadFile(path)
+		data = []byte(strings.Replace(string(data), `"id": "c-pets",`, `"id": "c-pets", "cookies": [{"name": "old", "value": "secret", "domain": "example.com", "path": "/"}],`, 1))
+		must(t, os.WriteFile(path, data, 0o644))
+
+		loaded, err := ReadFile()
+		must(t, err)
+		if got := loaded.Collections[0].Cookies; len(got) != 1 || got[0].Name != "old" {
+			t.Fatalf("cookies = %+v", got)
+		}
+		data, _ = os.ReadFile(path)
+		if strings.Contains(string(data), "secret") {
+			t.Errorf("data file still holds the cookie")
+		}
+		if local, _ := os.ReadFile(localStatePath()); !strings.Contains(string(local), "secret") {
+			t.Errorf("local state = %s", local)
+		}
+	})
+}
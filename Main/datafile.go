@@ -1,10 +1,13 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -13,31 +16,87 @@ import (
 )
 
 type Storage struct {
-	Collections []Collection `json:"collections"`
+	Version     int            `json:"version"`
+	Collections []Collection   `json:"collections"`
+	Settings    GlobalSettings `json:"settings,omitzero"`
 }
 type Collection struct {
+	ID             string          `json:"id"`
 	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
 	Requests       []Api           `json:"requests"`
+	Folders        []Folder        `json:"folders"`
 	LocalVariables []LocalVariable `json:"localVariables"`
+	Cookies        []Cookie        `json:"cookies,omitempty"`
+
+	Settings CollectionSettings `json:"settings,omitzero"`
+}
+
+// Folder groups requests inside a collection. Its headers, auth and
+// variables are inherited by everything nested below it.
+type Folder struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Requests       []Api           `json:"requests"`
+	Folders        []Folder        `json:"folders"`
+	Headers        []Header        `json:"headers"`
+	Auth           *Auth           `json:"auth,omitempty"`
+	LocalVariables []LocalVariable `json:"localVariables"`
+}
+
+type Auth struct {
+	Type     string `json:"type"`
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
 }
 
 type Header struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	ID       string `json:"id"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
 }
 type BodyField struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	ID       string `json:"id"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
 }
 
 type QueryParam struct {
+	ID       string `json:"id"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// PathParam fills a :name segment of the request URL.
+type PathParam struct {
+	ID    string `json:"id"`
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
 type LocalVariable struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	ID       string `json:"id"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// Cookie is a cookie in a collection's jar. Expires is a Unix time; zero
+// means the cookie lasts until it is cleared.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain"`
+	Path     string `json:"path"`
+	HostOnly bool   `json:"hostOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HttpOnly bool   `json:"httpOnly,omitempty"`
+	Expires  int64  `json:"expires,omitempty"`
 }
 
 type Response struct {
@@ -46,12 +105,102 @@ type Response struct {
 }
 
 type Api struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
 	Method      string       `json:"method"`
 	Url         string       `json:"url"`
 	Headers     []Header     `json:"headers"`
 	BodyField   []BodyField  `json:"bodyFields"`
 	QueryParams []QueryParam `json:"queryParams"`
+	PathParams  []PathParam  `json:"pathParams,omitempty"`
 	Responses   []Response   `json:"responses"`
+
+	Settings RequestSettings `json:"settings,omitzero"`
+}
+
+func (c Collection) itemID() string    { return c.ID }
+func (f Folder) itemID() string        { return f.ID }
+func (a Api) itemID() string           { return a.ID }
+func (h Header) itemID() string        { return h.ID }
+func (b BodyField) itemID() string     { return b.ID }
+func (q QueryParam) itemID() string    { return q.ID }
+func (p PathParam) itemID() string     { return p.ID }
+func (v LocalVariable) itemID() string { return v.ID }
+
+func (h Header) isEnabled() bool        { return !h.Disabled }
+func (b BodyField) isEnabled() bool     { return !b.Disabled }
+func (q QueryParam) isEnabled() bool    { return !q.Disabled }
+func (v LocalVariable) isEnabled() bool { return !v.Disabled }
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ensureIDs assigns IDs to anything added without one, e.g. by hand-editing
+// the data file, and reports whether it changed anything.
+func ensureIDs(storage *Storage) bool {
+	changed := false
+	for i := range storage.Collections {
+		collection := &storage.Collections[i]
+		changed = assignMissingID(&collection.ID) || changed
+		for j := range collection.LocalVariables {
+			changed = assignMissingID(&collection.LocalVariables[j].ID) || changed
+		}
+		for j := range collection.Requests {
+			changed = ensureApiIDs(&collection.Requests[j]) || changed
+		}
+		for j := range collection.Folders {
+			changed = ensureFolderIDs(&collection.Folders[j]) || changed
+		}
+	}
+	return changed
+}
+
+func ensureFolderIDs(folder *Folder) bool {
+	changed := assignMissingID(&folder.ID)
+	for i := range folder.Headers {
+		changed = assignMissingID(&folder.Headers[i].ID) || changed
+	}
+	for i := range folder.LocalVariables {
+		changed = assignMissingID(&folder.LocalVariables[i].ID) || changed
+	}
+	for i := range folder.Requests {
+		changed = ensureApiIDs(&folder.Requests[i]) || changed
+	}
+	for i := range folder.Folders {
+		changed = ensureFolderIDs(&folder.Folders[i]) || changed
+	}
+	return changed
+}
+
+func ensureApiIDs(api *Api) bool {
+	changed := assignMissingID(&api.ID)
+	for i := range api.Headers {
+		changed = assignMissingID(&api.Headers[i].ID) || changed
+	}
+	for i := range api.BodyField {
+		changed = assignMissingID(&api.BodyField[i].ID) || changed
+	}
+	for i := range api.QueryParams {
+		changed = assignMissingID(&api.QueryParams[i].ID) || changed
+	}
+	for i := range api.PathParams {
+		changed = assignMissingID(&api.PathParams[i].ID) || changed
+	}
+	return changed
+}
+
+func assignMissingID(id *string) bool {
+	if *id != "" {
+		return false
+	}
+	*id = newID()
+	return true
 }
 
 var fileName string = "APITEST1.json"
@@ -88,235 +237,71 @@ func fileChecker() error {
 }
 
 func ReadFile() (Storage, error) {
-	if err := fileChecker(); err != nil {
-		return Storage{}, err
-	}
-	file, err := os.ReadFile(fileName)
-	if err != nil {
-		return Storage{}, fmt.Errorf("failed to read file: %w", err)
-	}
-	var storage Storage
-	if len(file) == 0 {
-		return Storage{Collections: []Collection{}}, nil
-	}
-	if err := json.Unmarshal(file, &storage); err != nil {
-		return Storage{}, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-	return storage, nil
-}
-
-func AddApi(storage Storage, collectionIndex int, apis []Api, NewApiInput string) error {
-	parts := strings.SplitN(NewApiInput, " ", 2)
-	if collectionIndex < 0 || collectionIndex >= len(storage.Collections) {
-		return fmt.Errorf("invalid collection index")
-	}
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid format: expected 'METHOD URL' (e.g., 'GET https://api.com')")
-	}
-	if parts[0] == "" {
-		return fmt.Errorf("method cannot be empty")
-	}
-	if parts[1] == "" {
-		return fmt.Errorf("URL cannot be empty")
-	}
-	newApi := Api{
-		Method: parts[0],
-		Url:    parts[1],
-	}
-
-	apis = append(apis, newApi)
-	storage.Collections[collectionIndex].Requests = apis
-	return WriteFile(storage)
-}
-
-func AddCollection(storage Storage, collections []Collection, CollectionName string) error {
-	if CollectionName == "" {
-		return fmt.Errorf("collection name cannot be empty")
-	}
-	newCollection := Collection{
-		Name: CollectionName,
-	}
-	collections = append(collections, newCollection)
-	storage.Collections = collections
-	return WriteFile(storage)
-}
-
-func deleteApi(selectedApi Api, storage Storage, collectionIndex int) ([]Api, error) {
-	Apis := storage.Collections[collectionIndex].Requests
-	var newApis []Api
-	for i := 0; i < len(Apis); i++ {
-		if !(Apis[i].Url == selectedApi.Url && Apis[i].Method == selectedApi.Method) {
-			newApis = append(newApis, Apis[i])
-		}
-	}
-	storage.Collections[collectionIndex].Requests = newApis
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
-	}
-
-	return newApis, nil
-}
-func deleteCollection(selectedCollection Collection, storage Storage) ([]Collection, error) {
-	Collections := storage.Collections
-	var newCollections []Collection
-
-	for i := 0; i < len(Collections); i++ {
-		if !(Collections[i].Name == selectedCollection.Name) {
-			newCollections = append(newCollections, Collections[i])
-		}
-	}
-	storage.Collections = newCollections
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
-	}
-
-	return newCollections, nil
+	return readStorage(true)
 }
 
-func editApi(storage Storage, collectionIndex int, selectedApi Api, newApi string) error {
-	parts := strings.SplitN(newApi, " ", 2)
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid format: expected 'METHOD URL' (e.g., 'GET https://api.com')")
-	}
-	if parts[0] == "" {
-		return fmt.Errorf("method cannot be empty")
+// readStorage loads the workspace with the local state merged in. With
+// writeBack set, a file that had to be migrated is saved in the new format;
+// the file watcher leaves that to the UI so it never races with its saves.
+func readStorage(writeBack bool) (Storage, error) {
+	storage, migrated, err := readWorkspace(writeBack)
+	if err != nil {
+		return Storage{}, err
 	}
-	if parts[1] == "" {
-		return fmt.Errorf("URL cannot be empty")
+	state, err := readLocalState()
+	if err != nil {
+		return Storage{}, err
 	}
-	newApi1 := Api{
-		Method:      parts[0],
-		Url:         parts[1],
-		Headers:     selectedApi.Headers,
-		QueryParams: selectedApi.QueryParams,
-		BodyField:   selectedApi.BodyField,
+	if mergeLocalState(&storage, state) {
+		migrated = true
 	}
-
-	Apis := storage.Collections[collectionIndex].Requests
-	for i := 0; i < len(Apis); i++ {
-		if Apis[i].Method == selectedApi.Method && Apis[i].Url == selectedApi.Url {
-			Apis[i] = newApi1
+	if migrated && writeBack {
+		if err := WriteFile(storage); err != nil {
+			return Storage{}, fmt.Errorf("failed to save migrated data: %w", err)
 		}
 	}
-
-	return WriteFile(storage)
+	return storage, nil
 }
 
-func editCollection(storage Storage, selectedCollection Collection, newCollection string) error {
-	if newCollection == "" {
-		return fmt.Errorf("collection name cannot be empty")
+// readWorkspace reads the shared workspace and reports whether it had to be
+// migrated. A missing data file or directory is created when create is set.
+func readWorkspace(create bool) (Storage, bool, error) {
+	if dataDir != "" {
+		return readDirectory(create)
 	}
-	Collections := storage.Collections
-	for i := 0; i < len(Collections); i++ {
-		if Collections[i].Name == selectedCollection.Name {
-			Collections[i].Name = newCollection
-		}
-	}
-	return WriteFile(storage)
-}
-
-func addHeader(headers []Header, storage Storage, collectionIndex int, apiIndex int) error {
-
-	storage.Collections[collectionIndex].Requests[apiIndex].Headers = headers
-	return WriteFile(storage)
-}
-func deleteHeader(selectedHeader Header, storage Storage, collectionIndex int, apiIndex int) ([]Header, error) {
-	Headers := storage.Collections[collectionIndex].Requests[apiIndex].Headers
-
-	var newHeaders []Header
-	for i := 0; i < len(Headers); i++ {
-		if !(Headers[i].Key == selectedHeader.Key && Headers[i].Value == selectedHeader.Value) {
-			newHeaders = append(newHeaders, Headers[i])
+	if create {
+		if err := fileChecker(); err != nil {
+			return Storage{}, false, err
 		}
 	}
-	storage.Collections[collectionIndex].Requests[apiIndex].Headers = newHeaders
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
-	}
-
-	return newHeaders, nil
-}
-
-func addBodyField(storage Storage, collectionIndex int, apiIndex int, bodyFields []BodyField) ([]BodyField, error) {
-	storage.Collections[collectionIndex].Requests[apiIndex].BodyField = bodyFields
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
-	}
-
-	return bodyFields, nil
-}
-
-func deleteBodyField(selectedBodyField BodyField, storage Storage, collectionIndex int, apiIndex int) ([]BodyField, error) {
-	bodyFields := storage.Collections[collectionIndex].Requests[apiIndex].BodyField
-
-	var NewBodyFields []BodyField
-	for i := 0; i < len(bodyFields); i++ {
-		if !(bodyFields[i].Key == selectedBodyField.Key && bodyFields[i].Value == selectedBodyField.Value) {
-			NewBodyFields = append(NewBodyFields, bodyFields[i])
-		}
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return Storage{}, false, fmt.Errorf("failed to read file: %w", err)
 	}
-	storage.Collections[collectionIndex].Requests[apiIndex].BodyField = NewBodyFields
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
+	var storage Storage
+	if len(file) == 0 {
+		return Storage{Version: storageVersion, Collections: []Collection{}}, false, nil
 	}
-
-	return NewBodyFields, nil
-}
-
-func addQueryParam(queryParams []QueryParam, storage Storage, collectionIndex int, apiIndex int) error {
-	storage.Collections[collectionIndex].Requests[apiIndex].QueryParams = queryParams
-	return WriteFile(storage)
-}
-
-func deleteQueryParam(selectedQueryParam QueryParam, storage Storage, collectionIndex int, apiIndex int) ([]QueryParam, error) {
-	QueryParams := storage.Collections[collectionIndex].Requests[apiIndex].QueryParams
-
-	var newQueryParams []QueryParam
-	for i := 0; i < len(QueryParams); i++ {
-		if !(QueryParams[i].Key == selectedQueryParam.Key && QueryParams[i].Value == selectedQueryParam.Value) {
-			newQueryParams = append(newQueryParams, QueryParams[i])
-		}
+	file, migrated, err := migrateStorage(file)
+	if err != nil {
+		return Storage{}, false, err
 	}
-
-	storage.Collections[collectionIndex].Requests[apiIndex].QueryParams = newQueryParams
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
+	if err := json.Unmarshal(file, &storage); err != nil {
+		return Storage{}, false, fmt.Errorf("failed to parse JSON: %w", err)
 	}
-
-	return newQueryParams, nil
-}
-
-func addLocalVariable(storage Storage, collectionIndex int, localVariables []LocalVariable) error {
-	storage.Collections[collectionIndex].LocalVariables = localVariables
-	return WriteFile(storage)
+	return storage, migrated, nil
 }
 
-func deleteLocalVariable(selectedLocalVariable LocalVariable, storage Storage, collectionIndex int) ([]LocalVariable, error) {
-	LocalVariables := storage.Collections[collectionIndex].LocalVariables
-
-	var newLocalVariables []LocalVariable
-	for i := 0; i < len(LocalVariables); i++ {
-		if !(LocalVariables[i].Key == selectedLocalVariable.Key && LocalVariables[i].Value == selectedLocalVariable.Value) {
-			newLocalVariables = append(newLocalVariables, LocalVariables[i])
-		}
+// WriteFile saves the workspace, with cookies going to the local state file
+// instead.
+func WriteFile(storage Storage) error {
+	storage.Version = storageVersion
+	if err := writeLocalState(splitLocalState(&storage)); err != nil {
+		return err
 	}
-
-	storage.Collections[collectionIndex].LocalVariables = newLocalVariables
-
-	if err := WriteFile(storage); err != nil {
-		return nil, err
+	if dataDir != "" {
+		return writeDirectory(storage)
 	}
-
-	return newLocalVariables, nil
-}
-
-func WriteFile(storage Storage) error {
 	file, err := os.Create(fileName)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
@@ -338,7 +323,7 @@ func watchFile(p *tea.Program) (*fsnotify.Watcher, error) {
 		return nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
 
-	if err := watcher.Add(fileName); err != nil {
+	if err := addWatchPaths(watcher); err != nil {
 		watcher.Close()
 		return nil, fmt.Errorf("failed to watch file: %w", err)
 	}
@@ -347,8 +332,16 @@ func watchFile(p *tea.Program) (*fsnotify.Watcher, error) {
 		for {
 			select {
 			case event := <-watcher.Events:
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					newStorage, readErr := ReadFile()
+				if dataDir != "" && event.Op&fsnotify.Create == fsnotify.Create {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						watcher.Add(event.Name)
+					}
+				}
+				if name := filepath.Base(event.Name); name == localStateFileName || name == ".gitignore" || strings.HasSuffix(name, tempFileSuffix) {
+					continue
+				}
+				if event.Op&watchedOps() != 0 {
+					newStorage, readErr := readStorage(false)
 					if readErr != nil {
 						log.Printf("Watcher: Error reading file: %v", readErr)
 						continue
@@ -365,6 +358,28 @@ func watchFile(p *tea.Program) (*fsnotify.Watcher, error) {
 	return watcher, nil
 }
 
+func addWatchPaths(watcher *fsnotify.Watcher) error {
+	if dataDir == "" {
+		return watcher.Add(fileName)
+	}
+	return filepath.WalkDir(dataDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+func watchedOps() fsnotify.Op {
+	if dataDir == "" {
+		return fsnotify.Write
+	}
+	return fsnotify.Write | fsnotify.Create | fsnotify.Remove | fsnotify.Rename
+}
+
 func HandleJson(response ApiResponse) ([]Response, error) {
 	var vars []Response
 
@@ -0,0 +1,591 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dataDir switches storage to the directory layout: one folder per
+// collection holding a collection.json plus one pretty-printed file per request.
+var dataDir string
+
+const workspaceFileName = "workspace.json"
+const collectionFileName = "collection.json"
+const folderFileName = "folder.json"
+
+type workspaceMeta struct {
+	Version     int            `json:"version"`
+	Collections []string       `json:"collections"`
+	Settings    GlobalSettings `json:"settings,omitzero"`
+}
+
+type collectionMeta struct {
+	ID             string             `json:"id"`
+	Name           string             `json:"name"`
+	Description    string             `json:"description,omitempty"`
+	LocalVariables []LocalVariable    `json:"localVariables"`
+	Settings       CollectionSettings `json:"settings,omitzero"`
+	Requests       []string           `json:"requests"`
+	Folders        []string           `json:"folders"`
+
+	// Cookies is only read, from layouts written before cookies moved to
+	// the local state file.
+	Cookies []Cookie `json:"cookies,omitempty"`
+}
+
+type folderMeta struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Headers        []Header        `json:"headers"`
+	Auth           *Auth           `json:"auth,omitempty"`
+	LocalVariables []LocalVariable `json:"localVariables"`
+	Requests       []string        `json:"requests"`
+	Folders        []string        `json:"folders"`
+}
+
+func readDirectory(create bool) (Storage, bool, error) {
+	if create {
+		if err := os.MkdirAll(dataDir, 0o755); err != nil {
+			return Storage{}, false, fmt.Errorf("failed to create data directory: %w", err)
+		}
+	}
+
+	var workspace workspaceMeta
+	workspaceFile, err := os.ReadFile(filepath.Join(dataDir, workspaceFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return Storage{}, false, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+	if len(workspaceFile) > 0 {
+		if err := json.Unmarshal(workspaceFile, &workspace); err != nil {
+			return Storage{}, false, fmt.Errorf("failed to parse %s: %w", workspaceFileName, err)
+		}
+	}
+
+	dirs, err := collectionDirs(workspace.Collections)
+	if err != nil {
+		return Storage{}, false, err
+	}
+	if len(workspaceFile) == 0 && len(dirs) == 0 {
+		return Storage{Version: storageVersion, Collections: []Collection{}}, false, nil
+	}
+
+	var collections []interface{}
+	for _, dir := range dirs {
+		collection, err := readCollectionDir(filepath.Join(dataDir, dir))
+		if err != nil {
+			return Storage{}, false, err
+		}
+		collections = append(collections, collection)
+	}
+
+	doc := map[string]interface{}{"collections": collections, "settings": workspace.Settings}
+	if workspace.Version > 0 {
+		doc["version"] = workspace.Version
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return Storage{}, false, err
+	}
+	data, migrated, err := migrateStorage(data)
+	if err != nil {
+		return Storage{}, false, err
+	}
+
+	var storage Storage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return Storage{}, false, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return storage, migrated, nil
+}
+
+// collectionDirs returns the collection folders in workspace order, followed
+// by any folders that exist on disk but are missing from workspace.json.
+func collectionDirs(ordered []string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	var onDisk []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, entry.Name(), collectionFileName)); err != nil {
+			continue
+		}
+		onDisk = append(onDisk, entry.Name())
+	}
+	return orderNames(ordered, onDisk), nil
+}
+
+func readCollectionDir(dir string) (map[string]interface{}, error) {
+	return readNodeDir(dir, collectionFileName)
+}
+
+// readNodeDir reads a collection or folder directory: its metadata file plus
+// the request files and sub-folders it lists.
+func readNodeDir(dir string, metaFileName string) (map[string]interface{}, error) {
+	metaPath := filepath.Join(dir, metaFileName)
+	metaFile, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", metaPath, err)
+	}
+	var node map[string]interface{}
+	if err := json.Unmarshal(metaFile, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", metaPath, err)
+	}
+
+	requests := []interface{}{}
+	for _, name := range requestFiles(dir, stringList(node["requests"])) {
+		requestFile, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, name), err)
+		}
+		var request map[string]interface{}
+		if err := json.Unmarshal(requestFile, &request); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, name), err)
+		}
+		requests = append(requests, request)
+	}
+	node["requests"] = requests
+
+	folders := []interface{}{}
+	for _, name := range folderDirs(dir, stringList(node["folders"])) {
+		folder, err := readNodeDir(filepath.Join(dir, name), folderFileName)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	node["folders"] = folders
+	return node, nil
+}
+
+func stringList(value interface{}) []string {
+	var items []string
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		if name, ok := item.(string); ok {
+			items = append(items, name)
+		}
+	}
+	return items
+}
+
+// requestFiles returns the listed request files that exist, followed by any
+// other JSON files holding a request, e.g. ones added by hand. Other JSON
+// files are left alone.
+func requestFiles(dir string, ordered []string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	listed := map[string]bool{}
+	for _, name := range ordered {
+		listed[name] = true
+	}
+	var onDisk []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == collectionFileName || name == folderFileName || filepath.Ext(name) != ".json" {
+			continue
+		}
+		if !listed[name] && !isRequestFile(filepath.Join(dir, name)) {
+			continue
+		}
+		onDisk = append(onDisk, name)
+	}
+	return orderNames(ordered, onDisk)
+}
+
+func isRequestFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var request struct {
+		Method *string `json:"method"`
+	}
+	return json.Unmarshal(data, &request) == nil && request.Method != nil
+}
+
+// ownedRequestFiles are the request files the layout reads from a collection
+// or folder directory, going by the metadata file already there.
+func ownedRequestFiles(dir string) map[string]bool {
+	var listed []string
+	for _, metaFileName := range []string{collectionFileName, folderFileName} {
+		data, err := os.ReadFile(filepath.Join(dir, metaFileName))
+		if err != nil {
+			continue
+		}
+		var meta struct {
+			Requests []string `json:"requests"`
+		}
+		if json.Unmarshal(data, &meta) == nil {
+			listed = meta.Requests
+		}
+		break
+	}
+	owned := map[string]bool{}
+	for _, name := range requestFiles(dir, listed) {
+		owned[name] = true
+	}
+	return owned
+}
+
+// removeNodeDir removes a collection or folder directory that is no longer
+// in the workspace. Files the layout did not write are kept, and so is the
+// directory if any are left.
+func removeNodeDir(dir string, metaFileName string) error {
+	for name := range ownedRequestFiles(dir) {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", filepath.Join(dir, name), err)
+		}
+	}
+	for _, name := range folderDirs(dir, nil) {
+		if err := removeNodeDir(filepath.Join(dir, name), folderFileName); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, metaFileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", filepath.Join(dir, metaFileName), err)
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+		return os.Remove(dir)
+	}
+	return nil
+}
+
+func folderDirs(dir string, ordered []string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var onDisk []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), folderFileName)); err != nil {
+			continue
+		}
+		onDisk = append(onDisk, entry.Name())
+	}
+	return orderNames(ordered, onDisk)
+}
+
+// orderNames keeps the names listed in the metadata file first, in order,
+// followed by any other names found on disk.
+func orderNames(ordered []string, onDisk []string) []string {
+	exists := map[string]bool{}
+	for _, name := range onDisk {
+		exists[name] = true
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range ordered {
+		if exists[name] && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	sort.Strings(onDisk)
+	for _, name := range onDisk {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func writeDirectory(storage Storage) error {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	workspace := workspaceMeta{Version: storage.Version, Collections: []string{}, Settings: storage.Settings}
+	usedDirs := map[string]bool{}
+
+	ids := make([]string, len(storage.Collections))
+	bases := make([]string, len(storage.Collections))
+	for i, collection := range storage.Collections {
+		ids[i] = collection.ID
+		bases[i] = slugify(collection.Name, "collection")
+	}
+	dirs := keepNames(ids, bases, "", nodeDirIDs(dataDir, collectionFileName), usedDirs)
+	for i, collection := range storage.Collections {
+		workspace.Collections = append(workspace.Collections, dirs[i])
+		if err := writeCollectionDir(filepath.Join(dataDir, dirs[i]), collection); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSONFile(filepath.Join(dataDir, workspaceFileName), workspace); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to read data directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || usedDirs[entry.Name()] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, entry.Name(), collectionFileName)); err != nil {
+			continue
+		}
+		if err := removeNodeDir(filepath.Join(dataDir, entry.Name()), collectionFileName); err != nil {
+			return fmt.Errorf("failed to remove collection %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func writeCollectionDir(dir string, collection Collection) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create collection directory: %w", err)
+	}
+	requests, folders, err := writeNodeContents(dir, collection.Requests, collection.Folders)
+	if err != nil {
+		return err
+	}
+	meta := collectionMeta{
+		ID:             collection.ID,
+		Name:           collection.Name,
+		Description:    collection.Description,
+		LocalVariables: collection.LocalVariables,
+		Settings:       collection.Settings,
+		Requests:       requests,
+		Folders:        folders,
+	}
+	return writeJSONFile(filepath.Join(dir, collectionFileName), meta)
+}
+
+func writeFolderDir(dir string, folder Folder) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create folder directory: %w", err)
+	}
+	requests, folders, err := writeNodeContents(dir, folder.Requests, folder.Folders)
+	if err != nil {
+		return err
+	}
+	meta := folderMeta{
+		ID:             folder.ID,
+		Name:           folder.Name,
+		Description:    folder.Description,
+		Headers:        folder.Headers,
+		Auth:           folder.Auth,
+		LocalVariables: folder.LocalVariables,
+		Requests:       requests,
+		Folders:        folders,
+	}
+	return writeJSONFile(filepath.Join(dir, folderFileName), meta)
+}
+
+// writeNodeContents writes the request files and sub-folders of a collection
+// or folder, removes stale ones and returns the names in order. Only request
+// files the layout owned before are removed. A request or folder that already
+// has a file keeps its name when it is renamed.
+func writeNodeContents(dir string, apis []Api, folders []Folder) ([]string, []string, error) {
+	owned := ownedRequestFiles(dir)
+	usedFiles := map[string]bool{collectionFileName: true, folderFileName: true}
+	ids := make([]string, len(apis))
+	bases := make([]string, len(apis))
+	for i, api := range apis {
+		ids[i] = api.ID
+		bases[i] = strings.ToLower(api.Method) + "-" + slugify(api.Url, "request")
+		if api.Name != "" {
+			bases[i] = slugify(api.Name, "request")
+		}
+	}
+	requestNames := keepNames(ids, bases, ".json", requestFileIDs(dir, owned), usedFiles)
+	for i, api := range apis {
+		if err := writeJSONFile(filepath.Join(dir, requestNames[i]), api); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	usedDirs := map[string]bool{}
+	ids = make([]string, len(folders))
+	bases = make([]string, len(folders))
+	for i, folder := range folders {
+		ids[i] = folder.ID
+		bases[i] = slugify(folder.Name, "folder")
+	}
+	folderNames := keepNames(ids, bases, "", nodeDirIDs(dir, folderFileName), usedDirs)
+	for i, folder := range folders {
+		if err := writeFolderDir(filepath.Join(dir, folderNames[i]), folder); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if usedDirs[entry.Name()] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(path, folderFileName)); err != nil {
+				continue
+			}
+			if err := removeNodeDir(path, folderFileName); err != nil {
+				return nil, nil, fmt.Errorf("failed to remove folder %s: %w", entry.Name(), err)
+			}
+			continue
+		}
+		if usedFiles[entry.Name()] || !owned[entry.Name()] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, nil, fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		}
+	}
+	return requestNames, folderNames, nil
+}
+
+// requestFileIDs maps the ID in each owned request file to the file's name.
+func requestFileIDs(dir string, owned map[string]bool) map[string]string {
+	names := map[string]string{}
+	for name := range owned {
+		if id := fileID(filepath.Join(dir, name)); id != "" {
+			names[id] = name
+		}
+	}
+	return names
+}
+
+// nodeDirIDs maps the ID in the metadata file of each sub-directory of dir
+// to the directory's name.
+func nodeDirIDs(dir string, metaFileName string) map[string]string {
+	names := map[string]string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return names
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if id := fileID(filepath.Join(dir, entry.Name(), metaFileName)); id != "" {
+			names[id] = entry.Name()
+		}
+	}
+	return names
+}
+
+func fileID(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var item struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(data, &item) != nil {
+		return ""
+	}
+	return item.ID
+}
+
+// keepNames names each item: the name it already has on disk, or else a
+// unique one from its base. Existing names are taken first so a new item
+// never lands on the file of one that was renamed.
+func keepNames(ids []string, bases []string, ext string, existing map[string]string, used map[string]bool) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		if name, ok := existing[id]; ok && id != "" && !used[name] {
+			names[i] = name
+			used[name] = true
+		}
+	}
+	for i := range ids {
+		if names[i] == "" {
+			names[i] = uniqueName(bases[i], ext, used)
+		}
+	}
+	return names
+}
+
+// writeJSONFile skips files whose content is unchanged so that git diffs and
+// the file watcher only see real edits. Changed files are written to a temp
+// file first and renamed over the old one, so a crash never leaves half a
+// file behind.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	data = append(data, '\n')
+
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	if err := replaceFile(path, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// tempFileSuffix marks files being written. They are not .json files, so the
+// layout never reads one.
+const tempFileSuffix = ".tmp"
+
+func replaceFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*"+tempFileSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(text string, fallback string) string {
+	text = strings.ToLower(text)
+	if i := strings.Index(text, "://"); i >= 0 {
+		text = text[i+3:]
+	}
+	slug := strings.Trim(slugRe.ReplaceAllString(text, "-"), "-")
+	if len(slug) > 48 {
+		slug = strings.Trim(slug[:48], "-")
+	}
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
+
+func uniqueName(base string, ext string, used map[string]bool) string {
+	name := base + ext
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	used[name] = true
+	return name
+}
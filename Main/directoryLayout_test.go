@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useDataDir(t *testing.T) string {
+	t.Helper()
+	oldFile, oldDir := fileName, dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { fileName, dataDir = oldFile, oldDir })
+	return dataDir
+}
+
+func dirStorage() Storage {
+	return Storage{Collections: []Collection{{
+		ID: "c-pets", Name: "Pets", LocalVariables: []LocalVariable{},
+		Requests: []Api{{ID: "r-list", Method: "GET", Url: "/pets", Name: "List"}},
+		Folders: []Folder{{ID: "f-admin", Name: "Admin", Headers: []Header{}, LocalVariables: []LocalVariable{},
+			Requests: []Api{{ID: "r-stats", Method: "GET", Url: "/stats"}}}},
+	}}}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestDirectoryKeepsFilesItDoesNotOwn(t *testing.T) {
+	dir := useDataDir(t)
+	storage := dirStorage()
+	must(t, WriteFile(storage))
+	pets := filepath.Join(dir, "pets")
+	must(t, os.WriteFile(filepath.Join(pets, "notes.json"), []byte(`{"todo": "ask about auth"}`), 0o644))
+	must(t, os.WriteFile(filepath.Join(pets, "admin", "README.md"), []byte("admin endpoints"), 0o644))
+
+	loaded, err := ReadFile()
+	must(t, err)
+	if n := len(loaded.Collections[0].Requests); n != 1 {
+		t.Fatalf("read %d requests, notes.json should not be one", n)
+	}
+
+	storage.Collections[0].Folders = nil
+	must(t, WriteFile(storage))
+	if !exists(filepath.Join(pets, "notes.json")) {
+		t.Error("notes.json was deleted")
+	}
+	if !exists(filepath.Join(pets, "admin", "README.md")) || exists(filepath.Join(pets, "admin", folderFileName)) ||
+		exists(filepath.Join(pets, "admin", "get-stats.json")) {
+		t.Error("deleted folder should lose its own files and keep the others")
+	}
+}
+
+func TestDirectoryKeepsFileNamesOnRename(t *testing.T) {
+	dir := useDataDir(t)
+	storage := dirStorage()
+	must(t, WriteFile(storage))
+
+	storage.Collections[0].Name = "Animals"
+	storage.Collections[0].Requests[0].Name = "All pets"
+	storage.Collections[0].Folders[0].Name = "Staff"
+	// A new request named like the old one does not take over its file.
+	storage.Collections[0].Requests = append(storage.Collections[0].Requests, Api{ID: "r-new", Method: "GET", Url: "/new", Name: "List"})
+	must(t, WriteFile(storage))
+
+	pets := filepath.Join(dir, "pets")
+	for _, path := range []string{collectionFileName, "list.json", "list-2.json", filepath.Join("admin", folderFileName)} {
+		if !exists(filepath.Join(pets, path)) {
+			t.Errorf("%s missing after the rename", path)
+		}
+	}
+	if exists(filepath.Join(dir, "animals")) || exists(filepath.Join(pets, "all-pets.json")) || exists(filepath.Join(pets, "staff")) {
+		t.Error("renamed items moved to new files")
+	}
+	if id := fileID(filepath.Join(pets, "list.json")); id != "r-list" {
+		t.Errorf("list.json holds %q", id)
+	}
+	if temps, _ := filepath.Glob(filepath.Join(pets, "*"+tempFileSuffix)); len(temps) > 0 {
+		t.Errorf("temp files left behind: %v", temps)
+	}
+
+	loaded, err := ReadFile()
+	must(t, err)
+	if got := loaded.Collections[0]; got.Name != "Animals" || got.Requests[0].Name != "All pets" || got.Folders[0].Name != "Staff" {
+		t.Errorf("reloaded = %+v", got)
+	}
+}
+
+func TestDirectoryAdoptsHandAddedRequests(t *testing.T) {
+	dir := useDataDir(t)
+	must(t, WriteFile(dirStorage()))
+	added := filepath.Join(dir, "pets", "My Request.json")
+	must(t, os.WriteFile(added, []byte(`{"method": "DELETE", "url": "/pets/1", "headers": [{"key": "X-Reason", "value": "test"}]}`), 0o644))
+
+	loaded, err := ReadFile()
+	must(t, err)
+	if n := len(loaded.Collections[0].Requests); n != 2 {
+		t.Fatalf("read %d requests, want 2", n)
+	}
+	if headers := loaded.Collections[0].Requests[1].Headers; len(headers) != 1 || !headers[0].isEnabled() {
+		t.Errorf("hand-added headers = %+v, want one enabled header", headers)
+	}
+	must(t, WriteFile(loaded))
+	if exists(added) {
+		t.Error("hand-added file kept next to its rewritten copy")
+	}
+	loaded, err = ReadFile()
+	must(t, err)
+	if n := len(loaded.Collections[0].Requests); n != 2 {
+		t.Errorf("read %d requests after saving, want 2", n)
+	}
+}
+
+func TestWatcherReadDoesNotWrite(t *testing.T) {
+	path := useDataFile(t, "v0.json")
+	before, _ := os.ReadFile(path)
+	storage, err := readStorage(false)
+	must(t, err)
+	if storage.Version != storageVersion || len(storage.Collections) != 2 {
+		t.Errorf("storage = %+v", storage)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("watcher read rewrote the data file")
+	}
+
+	fileName = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := readStorage(false); err == nil || exists(fileName) {
+		t.Error("watcher read created a missing data file")
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// apiTitle is how a request is labelled in lists: its name when it has one,
+// otherwise its URL.
+func apiTitle(api Api) string {
+	if api.Name != "" {
+		return api.Name
+	}
+	return displayURL(api)
+}
+
+var (
+	markdownBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownCode   = regexp.MustCompile("`([^`]+)`")
+	markdownLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	headingStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	codeBlockStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("150"))
+	inlineCode     = lipgloss.NewStyle().Foreground(lipgloss.Color("150"))
+	boldStyle      = lipgloss.NewStyle().Bold(true)
+	quoteStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+)
+
+// renderMarkdown renders the subset of markdown used in descriptions for the
+// terminal: headings, lists, quotes, fenced code, bold, inline code and links.
+func renderMarkdown(text string, width int) string {
+	if strings.TrimSpace(text) == "" {
+		return quoteStyle.Render("No description. Press 'e' to write one.")
+	}
+	wrap := lipgloss.NewStyle().Width(max(width, 20))
+
+	var lines []string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			lines = append(lines, codeBlockStyle.Render("  "+line))
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			lines = append(lines, headingStyle.Render(heading))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			lines = append(lines, wrap.Render("  • "+renderInline(trimmed[2:])))
+		case strings.HasPrefix(trimmed, "> "):
+			lines = append(lines, quoteStyle.Render("│ "+trimmed[2:]))
+		default:
+			lines = append(lines, wrap.Render(renderInline(line)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderInline(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1 <$2>")
+	text = markdownCode.ReplaceAllStringFunc(text, func(match string) string {
+		return inlineCode.Render(strings.Trim(match, "`"))
+	})
+	return markdownBold.ReplaceAllStringFunc(text, func(match string) string {
+		return boldStyle.Render(strings.Trim(match, "*"))
+	})
+}
+
+// collectionMarkdown builds a markdown API reference for a collection. The
+// reference is meant to be shared, so it holds requests as written and never
+// the values of variables or credentials: variables are listed by name and
+// auth by its type.
+func collectionMarkdown(collection Collection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", collection.Name)
+	if collection.Description != "" {
+		b.WriteString(strings.TrimSpace(collection.Description) + "\n\n")
+	}
+	if variables := enabledItems(collection.LocalVariables); len(variables) > 0 {
+		b.WriteString("## Variables\n\n")
+		for _, variable := range variables {
+			fmt.Fprintf(&b, "- `%s`\n", markdownCell(variable.Key))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, api := range collection.Requests {
+		writeApiMarkdown(&b, collection, api, "##")
+	}
+	writeFolderMarkdown(&b, collection, collection.Folders, "")
+	return b.String()
+}
+
+func writeFolderMarkdown(b *strings.Builder, collection Collection, folders []Folder, prefix string) {
+	for _, folder := range folders {
+		path := prefix + folder.Name + "/"
+		fmt.Fprintf(b, "## %s\n\n", path)
+		if folder.Description != "" {
+			b.WriteString(strings.TrimSpace(folder.Description) + "\n\n")
+		}
+		for _, api := range folder.Requests {
+			writeApiMarkdown(b, collection, api, "###")
+		}
+		writeFolderMarkdown(b, collection, folder.Folders, path)
+	}
+}
+
+func writeApiMarkdown(b *strings.Builder, collection Collection, api Api, level string) {
+	fmt.Fprintf(b, "%s %s\n\n", level, apiTitle(api))
+	fmt.Fprintf(b, "```\n%s %s\n```\n\n", api.Method, api.Url)
+	if api.Description != "" {
+		b.WriteString(strings.TrimSpace(api.Description) + "\n\n")
+	}
+	if auth, folder := inheritedAuth(collection, api); auth != nil {
+		fmt.Fprintf(b, "**Auth:** %s (inherited from folder %s)\n\n", authTypeLabel(auth.Type), folder.Name)
+	}
+	if headers := enabledItems(api.Headers); len(headers) > 0 {
+		b.WriteString("**Headers**\n\n| Name | Value |\n| --- | --- |\n")
+		for _, header := range headers {
+			fmt.Fprintf(b, "| `%s` | `%s` |\n", markdownCell(header.Key), markdownCell(documentedHeaderValue(header)))
+		}
+		b.WriteString("\n")
+	}
+	if params := enabledItems(api.QueryParams); len(params) > 0 {
+		b.WriteString("**Query parameters**\n\n| Name | Value |\n| --- | --- |\n")
+		for _, param := range params {
+			fmt.Fprintf(b, "| `%s` | `%s` |\n", markdownCell(param.Key), markdownCell(param.Value))
+		}
+		b.WriteString("\n")
+	}
+	if len(api.PathParams) > 0 {
+		b.WriteString("**Path parameters**\n\n| Name | Value |\n| --- | --- |\n")
+		for _, param := range api.PathParams {
+			fmt.Fprintf(b, "| `%s` | `%s` |\n", markdownCell(param.Key), markdownCell(param.Value))
+		}
+		b.WriteString("\n")
+	}
+	if len(enabledItems(api.BodyField)) > 0 {
+		fmt.Fprintf(b, "**Body**\n\n```json\n%s\n```\n\n", parseData(api, nil))
+	}
+}
+
+// inheritedAuth returns the auth a request gets from its closest folder that
+// sets one.
+func inheritedAuth(collection Collection, api Api) (*Auth, *Folder) {
+	path, _ := folderPath(collection.Folders, api.ID)
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Auth != nil {
+			return path[i].Auth, path[i]
+		}
+	}
+	return nil, nil
+}
+
+func authTypeLabel(authType string) string {
+	switch authType {
+	case "bearer":
+		return "Bearer"
+	case "basic":
+		return "Basic"
+	}
+	return authType
+}
+
+// documentedHeaderValue hides credentials typed straight into a header. A
+// value such as "Bearer {{token}}" only names a variable and stays.
+func documentedHeaderValue(header Header) string {
+	switch strings.ToLower(header.Key) {
+	case "authorization", "proxy-authorization", "cookie", "x-api-key":
+		rest := placeholderPattern.ReplaceAllString(header.Value, "")
+		if !placeholderPattern.MatchString(header.Value) || len(strings.Fields(rest)) > 1 {
+			return "<redacted>"
+		}
+	}
+	return header.Value
+}
+
+func markdownCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "|", `\|`), "\n", " ")
+}
+
+func markdownHTML(title string, markdown string) (string, error) {
+	var body bytes.Buffer
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	if err := md.Convert([]byte(markdown), &body); err != nil {
+		return "", fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+pre { background: #f4f4f4; padding: .75em; overflow-x: auto; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: .25em .5em; text-align: left; }
+</style>
+</head>
+<body>
+%s</body>
+</html>
+`, html.EscapeString(title), body.String()), nil
+}
+
+// generateDocs writes an API reference for the named collection, or for every
+// collection when name is empty. A path ending in .html produces HTML,
+// anything else markdown; "-" writes markdown to stdout.
+func generateDocs(storage Storage, name string, path string) error {
+	var parts []string
+	for _, collection := range storage.Collections {
+		if name == "" || collection.Name == name {
+			parts = append(parts, collectionMarkdown(collection))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("collection %q not found", name)
+	}
+
+	output := strings.Join(parts, "\n")
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".html" || ext == ".htm" {
+		title := name
+		if title == "" {
+			title = "API Reference"
+		}
+		page, err := markdownHTML(title, output)
+		if err != nil {
+			return err
+		}
+		output = page
+	}
+
+	if path == "-" {
+		_, err := os.Stdout.WriteString(output)
+		return err
+	}
+	if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+		return fmt.Errorf("failed to write docs: %w", err)
+	}
+	return nil
+}
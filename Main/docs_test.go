@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollectionMarkdownLeavesOutSecrets(t *testing.T) {
+	collection := Collection{
+		Name:           "Pets",
+		LocalVariables: []LocalVariable{{Key: "token", Value: "s3cret-variable"}},
+		Folders: []Folder{{
+			ID:      "f1",
+			Name:    "admin",
+			Auth:    &Auth{Type: "basic", Username: "root", Password: "hunter2"},
+			Headers: []Header{{Key: "X-Folder", Value: "{{token}}"}},
+			Requests: []Api{{
+				ID:     "r1",
+				Method: "GET",
+				Url:    "{{base}}/pets",
+				Headers: []Header{
+					{Key: "Authorization", Value: "Bearer literal-token"},
+					{Key: "X-Api-Key", Value: "{{token}}"},
+					{Key: "Accept", Value: "application/json"},
+				},
+			}},
+		}},
+	}
+
+	doc := collectionMarkdown(collection)
+	for _, secret := range []string{"s3cret-variable", "hunter2", "literal-token", "cm9vdDpodW50ZXIy"} {
+		if strings.Contains(doc, secret) {
+			t.Errorf("reference contains %q:\n%s", secret, doc)
+		}
+	}
+	for _, want := range []string{
+		"- `token`",
+		"**Auth:** Basic (inherited from folder admin)",
+		"| `Authorization` | `<redacted>` |",
+		"| `X-Api-Key` | `{{token}}` |",
+		"| `Accept` | `application/json` |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("reference is missing %q:\n%s", want, doc)
+		}
+	}
+}
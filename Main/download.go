@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxBodyInMemory is how much of a response body is kept for display. Larger
+// bodies are streamed to a temp file in full.
+const maxBodyInMemory = 10 << 20
+
+// maxBodyShown is how much of the body the response page renders. Styling
+// megabytes of text would make every redraw slow; the rest can be saved.
+const maxBodyShown = 256 << 10
+
+const progressInterval = 100 * time.Millisecond
+
+type progressFunc func(received int64, total int64)
+
+type downloadProgressMsg struct {
+	received int64
+	total    int64
+	events   chan tea.Msg
+}
+
+type progressReader struct {
+	reader   io.Reader
+	received int64
+	total    int64
+	progress progressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.received += int64(n)
+	if r.progress != nil {
+		r.progress(r.received, r.total)
+	}
+	return n, err
+}
+
+// readBody reads a response body, keeping at most maxBodyInMemory bytes, cut
+// on a character boundary. When the body is larger the whole of it is written
+// to a temp file, whose path is returned with the full size.
+func readBody(reader io.Reader) (string, string, int64, error) {
+	var head bytes.Buffer
+	n, err := io.CopyN(&head, reader, maxBodyInMemory+1)
+	if err == io.EOF {
+		return head.String(), "", n, nil
+	}
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	file, err := os.CreateTemp("", "gotui-body-*")
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(head.Bytes()); err != nil {
+		os.Remove(file.Name())
+		return "", "", 0, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	rest, err := io.Copy(file, reader)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", "", 0, err
+	}
+	text := head.String()
+	return text[:runeStart(text, maxBodyInMemory)], file.Name(), n + rest, nil
+}
+
+// startRequest runs send in the background. Progress and the final response
+// arrive as messages through the returned command; esc on the LoadingPage
+// cancels the request.
+func (m *model) startRequest(send func(ctx context.Context, progress progressFunc) ApiResponse) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan tea.Msg, 1)
+	m.cancelRequest = cancel
+	m.requestEvents = events
+	m.download = downloadProgressMsg{total: -1}
+	m.CurrentPage = LoadingPage
+
+	go func() {
+		defer cancel()
+		var last time.Time
+		progress := func(received int64, total int64) {
+			if time.Since(last) < progressInterval {
+				return
+			}
+			last = time.Now()
+			select {
+			case events <- downloadProgressMsg{received: received, total: total, events: events}:
+			default:
+			}
+		}
+		events <- apiResponseMsg{response: send(ctx, progress), events: events}
+	}()
+	return waitForRequest(events)
+}
+
+func waitForRequest(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+func (m *model) cancelPendingRequest() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+}
+
+// suggestedFileName picks a file name for saving a response from its
+// Content-Disposition header or the last segment of the URL.
+func suggestedFileName(response ApiResponse, rawURL string) string {
+	if _, params, err := mime.ParseMediaType(response.Headers.Get("Content-Disposition")); err == nil {
+		if name := filepath.Base(params["filename"]); params["filename"] != "" && name != "." && name != "/" {
+			return name
+		}
+	}
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	if name := path.Base(rawURL); name != "." && name != "/" && !strings.Contains(name, ":") {
+		return name
+	}
+	return "response.txt"
+}
+
+// saveResponse writes the full response body to target, optionally preceded
+// by the status line and headers.
+func saveResponse(response ApiResponse, target string, withHeaders bool) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("no file name given")
+	}
+	file, err := os.Create(expandHome(target))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if withHeaders {
+		fmt.Fprintf(file, "%s\n", response.Status)
+		if err := response.Headers.Write(file); err != nil {
+			return err
+		}
+		fmt.Fprintln(file)
+	}
+
+	if response.BodyFile == "" {
+		_, err = file.WriteString(response.Body)
+		return err
+	}
+	body, err := os.Open(response.BodyFile)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(file, body)
+	return err
+}
+
+// shownBody cuts body to at most maxBodyShown bytes, at the end of a line
+// where there is one, and reports whether it had to.
+func shownBody(body string) (string, bool) {
+	if len(body) <= maxBodyShown {
+		return body, false
+	}
+	cut := maxBodyShown
+	if i := strings.LastIndexByte(body[:cut], '\n'); i > 0 {
+		cut = i
+	}
+	return body[:runeStart(body, cut)], true
+}
+
+// runeStart moves i back to the start of the rune it falls in, so cutting
+// text there never splits a multi-byte character.
+func runeStart(text string, i int) int {
+	for i > 0 && i < len(text) && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
+
+// expandHome resolves a leading ~/ to the user's home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for value := n / unit; value >= unit; value /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
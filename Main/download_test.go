@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestShownBody(t *testing.T) {
+	if body, cut := shownBody("short"); body != "short" || cut {
+		t.Errorf("shownBody(short) = %q, %v", body, cut)
+	}
+
+	lines := strings.Repeat("0123456789abcdef\n", maxBodyShown/17+10)
+	body, cut := shownBody(lines)
+	if !cut || len(body) > maxBodyShown || !strings.HasSuffix(body, "f") {
+		t.Errorf("cut lines: %d bytes, cut %v, ends %q", len(body), cut, body[len(body)-3:])
+	}
+
+	runes := strings.Repeat("é", maxBodyShown)
+	body, cut = shownBody(runes)
+	if !cut || len(body) > maxBodyShown || !utf8.ValidString(body) {
+		t.Errorf("cut runes: %d bytes, cut %v, valid %v", len(body), cut, utf8.ValidString(body))
+	}
+}
+
+func TestReadBodyKeepsWholeRunes(t *testing.T) {
+	// One ASCII byte first puts a rune boundary off the cut.
+	full := "x" + strings.Repeat("é", maxBodyInMemory/2)
+	body, file, size, err := readBody(strings.NewReader(full))
+	must(t, err)
+	defer os.Remove(file)
+	if file == "" || size != int64(len(full)) {
+		t.Fatalf("file %q, size %d", file, size)
+	}
+	if len(body) > maxBodyInMemory || !utf8.ValidString(body) || !strings.HasPrefix(full, body) {
+		t.Errorf("kept %d bytes, valid %v", len(body), utf8.ValidString(body))
+	}
+	if saved, err := os.ReadFile(file); err != nil || string(saved) != full {
+		t.Errorf("temp file has %d bytes, %v", len(saved), err)
+	}
+}
+
+func TestQuitRemovesBodyFile(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "gotui-body-*")
+	must(t, err)
+	file.Close()
+	m := NewModel(NewMemoryStore(Storage{Version: storageVersion}), Storage{Version: storageVersion})
+	m.apiResponse = ApiResponse{BodyFile: file.Name()}
+
+	m = press(m, keyEsc)
+	if _, err := os.Stat(file.Name()); !os.IsNotExist(err) {
+		t.Errorf("body file left behind: %v", err)
+	}
+}
+
+func TestStaleResponseIgnored(t *testing.T) {
+	m := NewModel(NewMemoryStore(Storage{Version: storageVersion}), Storage{Version: storageVersion})
+	m.startRequest(func(ctx context.Context, progress progressFunc) ApiResponse {
+		<-ctx.Done()
+		return ApiResponse{}
+	})
+	old := m.requestEvents
+	m.cancelPendingRequest()
+	cmd := m.startRequest(func(ctx context.Context, progress progressFunc) ApiResponse {
+		return ApiResponse{StatusCode: 200, Status: "200 OK", Body: "new"}
+	})
+
+	updated, _ := m.Update(apiResponseMsg{response: ApiResponse{StatusCode: 500, Body: "old"}, events: old})
+	m = updated.(model)
+	if m.CurrentPage != LoadingPage || m.apiResponse.Body != "" {
+		t.Fatalf("stale response shown: page %v, body %q", m.CurrentPage, m.apiResponse.Body)
+	}
+
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	if m.CurrentPage != ApiPage || m.apiResponse.Body != "new" {
+		t.Errorf("current response not shown: page %v, body %q", m.CurrentPage, m.apiResponse.Body)
+	}
+}
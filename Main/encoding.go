@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/net/html/charset"
+)
+
+// acceptEncoding is sent when the request does not set Accept-Encoding
+// itself. Responses are always decoded here rather than by net/http, so a
+// manually set header works the same way.
+const acceptEncoding = "gzip, deflate, br"
+
+// decodeBody undoes the Content-Encoding of a response and converts text in
+// other charsets to UTF-8. It returns the decoded reader and a description
+// of what was done, e.g. "gzip, charset iso-8859-1".
+func decodeBody(body io.Reader, header http.Header) (io.Reader, string, error) {
+	var steps []string
+
+	encodings := strings.Split(header.Get("Content-Encoding"), ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		var err error
+		switch encoding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = deflateReader(body)
+		case "br":
+			body = brotli.NewReader(body)
+		default:
+			steps = append(steps, encoding+" not decoded")
+			return body, strings.Join(steps, ", "), nil
+		}
+		if err == io.EOF {
+			return strings.NewReader(""), encoding, nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode %s body: %w", encoding, err)
+		}
+		steps = append(steps, encoding)
+	}
+
+	decoded, name := charsetReader(body, header.Get("Content-Type"))
+	if name != "" {
+		steps = append(steps, "charset "+name)
+	}
+	return decoded, strings.Join(steps, ", "), nil
+}
+
+// deflateReader accepts both zlib-wrapped deflate, which is what the spec
+// asks for, and the raw deflate some servers send instead.
+func deflateReader(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// charsetReader converts the body to UTF-8 when the Content-Type names
+// another charset, or for HTML whose meta tags do. Anything else is left
+// alone so binary bodies are not mangled.
+func charsetReader(body io.Reader, contentType string) (io.Reader, string) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	label := strings.ToLower(params["charset"])
+
+	if label == "" && mediaType == "text/html" {
+		buffered := bufio.NewReader(body)
+		sample, _ := buffered.Peek(1024)
+		encoding, name, certain := charset.DetermineEncoding(sample, contentType)
+		// Without a BOM or meta tag the guess is windows-1252, which is
+		// only trusted for text that is not valid UTF-8.
+		if name == "utf-8" || name == "windows-1252" && !certain && utf8.Valid(sample) {
+			return buffered, ""
+		}
+		return encoding.NewDecoder().Reader(buffered), name
+	}
+
+	if label == "" || label == "utf-8" || label == "utf8" || label == "us-ascii" {
+		return body, ""
+	}
+	decoded, err := charset.NewReaderLabel(label, body)
+	if err != nil {
+		return body, label + " not decoded"
+	}
+	return decoded, label
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,67 +13,61 @@ import (
 type ApiResponse struct {
 	StatusCode     int
 	Status         string
+	Proto          string
 	Body           string
 	Headers        http.Header
 	RequestHeaders []Header
 	ContentType    string
 	ContentLength  int64
+	BodySize       int64
+	BodyFile       string
+	WireSize       int64
+	Decoding       string
+	Cookies        []Cookie
+	CookiesChanged bool
+
+	Redirects       []RedirectHop
+	RedirectLimit   bool
+	FollowRedirects bool
+
+	TLS      *TLSInfo
+	Proxy    string
+	Attempts []RetryAttempt
 }
 
-func FetchData(SelectedApi Api, m model) ApiResponse {
-	processedApi := processRequest(SelectedApi, m.SelectedCollection.LocalVariables)
+func FetchData(ctx context.Context, SelectedApi Api, m model, progress progressFunc) ApiResponse {
+	resolvedApi, variables := resolveRequest(m.SelectedCollection, SelectedApi)
+	processedApi := processRequest(resolvedApi, variables)
 
 	headers := processedApi.Headers
-	api := buildURL(processedApi, m)
+	api := buildURL(processedApi, variables)
 
 	url := strings.TrimSpace(api)
 	url = strings.Trim(url, `"`)
 
 	method := processedApi.Method
 
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return ApiResponse{StatusCode: 0, Status: err.Error()}
 	}
 
 	for i := 0; i < len(headers); i++ {
-		req.Header.Set(headers[i].Key, replaceVariables(headers[i].Value, m.LocalVariables))
+		req.Header.Set(headers[i].Key, replaceVariables(headers[i].Value, variables))
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return ApiResponse{StatusCode: 0, Status: err.Error()}
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return ApiResponse{StatusCode: 0, Status: "Failed to read Response : " + err.Error()}
-	}
-
-	m.apiResponse = ApiResponse{
-		StatusCode:     resp.StatusCode,
-		Status:         resp.Status,
-		Body:           string(bodyBytes),
-		Headers:        resp.Header,
-		RequestHeaders: SelectedApi.Headers,
-		ContentType:    resp.Header.Get("Content-Type"),
-		ContentLength:  resp.ContentLength,
-	}
-
-	return m.apiResponse
-
+	return sendRequest(req, resolvedApi, m.SelectedCollection, m.storage.Settings, progress)
 }
 
-func PostAPiFunc(m model) ApiResponse {
-	SelectedApi := processRequest(m.SelectedApi, m.LocalVariables)
+func PostAPiFunc(ctx context.Context, m model, progress progressFunc) ApiResponse {
+	resolvedApi, variables := resolveRequest(m.SelectedCollection, m.SelectedApi)
+	SelectedApi := processRequest(resolvedApi, variables)
 
-	headers := m.SelectedApi.Headers
+	headers := resolvedApi.Headers
 
-	data := parseData(SelectedApi, m)
+	data := parseData(SelectedApi, variables)
 
-	Url := buildURL(SelectedApi, m)
+	Url := buildURL(SelectedApi, variables)
 	bodyReader := strings.NewReader(data)
 
 	url := strings.TrimSpace(Url)
@@ -81,7 +75,7 @@ func PostAPiFunc(m model) ApiResponse {
 
 	method := SelectedApi.Method
 
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return ApiResponse{StatusCode: 0, Status: err.Error()}
 	}
@@ -94,37 +88,102 @@ func PostAPiFunc(m model) ApiResponse {
 	headers = append(headers, newHeader)
 
 	for i := 0; i < len(headers); i++ {
-		req.Header.Set(headers[i].Key, replaceVariables(headers[i].Value, m.LocalVariables))
+		req.Header.Set(headers[i].Key, replaceVariables(headers[i].Value, variables))
 	}
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	return sendRequest(req, resolvedApi, m.SelectedCollection, m.storage.Settings, progress)
+}
+
+func newTransport(request RequestSettings, settings CollectionSettings, global GlobalSettings) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableCompression = true
+	transport.Protocols = request.protocols()
+
+	proxy, err := proxyFunc(effectiveProxy(global.Proxy, settings.Proxy))
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	config, err := tlsConfig(settings.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		transport.TLSClientConfig = config
+	}
+	return transport, nil
+}
+
+func sendRequest(req *http.Request, api Api, collection Collection, global GlobalSettings, progress progressFunc) ApiResponse {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	transport, err := newTransport(api.Settings, collection.Settings, global)
 	if err != nil {
 		return ApiResponse{StatusCode: 0, Status: err.Error()}
 	}
+	// Each request gets its own transport, so its keep-alive connections
+	// would otherwise stay open until the server drops them.
+	defer transport.CloseIdleConnections()
+	var usedProxy *url.URL
+	if proxy := transport.Proxy; proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			u, err := proxy(req)
+			usedProxy = u
+			return u, err
+		}
+	}
+	jar := newCookieJar(collection.Cookies)
+	redirects := newRedirectRecorder(api.Settings)
+	client := &http.Client{Transport: transport, Jar: jar, CheckRedirect: redirects.checkRedirect}
+	policy := effectiveRetry(api.Settings.Retry, collection.Settings.Retry)
+	resp, attempts, err := doWithRetries(client, req, policy, redirects)
+	if err != nil {
+		return ApiResponse{StatusCode: 0, Status: err.Error(), Redirects: redirects.hops, Proxy: describeProxy(usedProxy), Attempts: attempts}
+	}
 	defer resp.Body.Close()
 
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
+	wire := &progressReader{reader: resp.Body, total: resp.ContentLength, progress: progress}
+	decoded, decoding, err := decodeBody(wire, resp.Header)
+	if err != nil {
+		return ApiResponse{StatusCode: 0, Status: "Failed to read Response : " + err.Error()}
+	}
+	body, bodyFile, size, err := readBody(decoded)
 	if err != nil {
 		return ApiResponse{StatusCode: 0, Status: "Failed to read Response : " + err.Error()}
 	}
 
-	m.apiResponse = ApiResponse{
+	cookies, cookiesChanged := jar.contents()
+	return ApiResponse{
 		StatusCode:     resp.StatusCode,
 		Status:         resp.Status,
-		Body:           string(bodyBytes),
+		Proto:          resp.Proto,
+		Body:           body,
 		Headers:        resp.Header,
-		RequestHeaders: m.SelectedApi.Headers,
+		RequestHeaders: api.Headers,
 		ContentType:    resp.Header.Get("Content-Type"),
 		ContentLength:  resp.ContentLength,
+		BodySize:       size,
+		BodyFile:       bodyFile,
+		WireSize:       wire.received,
+		Decoding:       decoding,
+		Cookies:        cookies,
+		CookiesChanged: cookiesChanged,
+
+		Redirects:       redirects.hops,
+		RedirectLimit:   redirects.limitReached,
+		FollowRedirects: redirects.follow,
+
+		TLS:      newTLSInfo(resp.TLS),
+		Proxy:    describeProxy(usedProxy),
+		Attempts: attempts,
 	}
-
-	return m.apiResponse
 }
-func parseData(selectedApi Api, m model) string {
-	if len(selectedApi.BodyField) == 0 {
+func parseData(selectedApi Api, variables []LocalVariable) string {
+	fields := enabledItems(selectedApi.BodyField)
+	if len(fields) == 0 {
 		return "{}"
 	}
 
@@ -132,49 +191,58 @@ func parseData(selectedApi Api, m model) string {
 	var b strings.Builder
 	b.WriteString("{\n")
 
-	for i, field := range selectedApi.BodyField {
+	for i, field := range fields {
 		// Add key-value pair
 		b.WriteString(fmt.Sprintf("  \"%s\": \"%s\"", field.Key, field.Value))
 
 		// Add comma if not the last item
-		if i < len(selectedApi.BodyField)-1 {
+		if i < len(fields)-1 {
 			b.WriteString(",")
 		}
 		b.WriteString("\n")
 	}
 
 	b.WriteString("}")
-	return replaceVariables(b.String(), m.LocalVariables)
+	return replaceVariables(b.String(), variables)
 }
 
 type apiResponseMsg struct {
 	response ApiResponse
+	// events identifies the request, so the answer to one that was
+	// replaced by a newer request is dropped.
+	events chan tea.Msg
 }
 
-func fetchApiCommand(api Api, m model) tea.Cmd {
-	return func() tea.Msg {
-		response := FetchData(api, m)
-		return apiResponseMsg{response: response}
-	}
+func (m *model) fetchApiCommand(api Api) tea.Cmd {
+	snapshot := *m
+	return m.startRequest(func(ctx context.Context, progress progressFunc) ApiResponse {
+		return FetchData(ctx, api, snapshot, progress)
+	})
 }
 
-func postApiCommand(m model) tea.Cmd {
-	return func() tea.Msg {
-		response := PostAPiFunc(m)
-		return apiResponseMsg{response: response}
-	}
+func (m *model) postApiCommand() tea.Cmd {
+	snapshot := *m
+	return m.startRequest(func(ctx context.Context, progress progressFunc) ApiResponse {
+		return PostAPiFunc(ctx, snapshot, progress)
+	})
 }
-func buildURL(api Api, m model) string {
-	if len(api.QueryParams) == 0 {
-		return api.Url
+func buildURL(api Api, variables []LocalVariable) string {
+	base := applyPathParams(api.Url, api.PathParams, variables)
+	queryParams := enabledItems(api.QueryParams)
+	if len(queryParams) == 0 {
+		return base
 	}
 
 	var params []string
-	for _, param := range api.QueryParams {
-		params = append(params, url.QueryEscape(param.Key)+"="+url.QueryEscape(replaceVariables(param.Value, m.LocalVariables)))
+	for _, param := range queryParams {
+		params = append(params, url.QueryEscape(param.Key)+"="+url.QueryEscape(replaceVariables(param.Value, variables)))
 	}
 
-	return api.Url + "?" + strings.Join(params, "&")
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + strings.Join(params, "&")
 }
 
 func processRequest(api Api, variables []LocalVariable) Api {
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendRequestClosesConnections(t *testing.T) {
+	var mu sync.Mutex
+	open := map[net.Conn]bool{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch state {
+		case http.StateNew:
+			open[conn] = true
+		case http.StateClosed, http.StateHijacked:
+			delete(open, conn)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for range 3 {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		if resp := sendRequest(req, Api{}, Collection{}, GlobalSettings{}, nil); resp.StatusCode != 200 {
+			t.Fatalf("status = %d %s", resp.StatusCode, resp.Status)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(open)
+		mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections still open after the requests finished", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
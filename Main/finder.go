@@ -0,0 +1,153 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// finderEntry is one request in the command palette, with the collection and
+// folders it lives in.
+type finderEntry struct {
+	CollectionID string
+	Api          Api
+	Path         string
+	score        int
+}
+
+func finderEntries(storage Storage) []finderEntry {
+	var entries []finderEntry
+	for _, collection := range storage.Collections {
+		for _, api := range collection.Requests {
+			entries = append(entries, finderEntry{CollectionID: collection.ID, Api: api, Path: collection.Name})
+		}
+		var walk func(folders []Folder, path string)
+		walk = func(folders []Folder, path string) {
+			for _, folder := range folders {
+				folderPath := path + "/" + folder.Name
+				for _, api := range folder.Requests {
+					entries = append(entries, finderEntry{CollectionID: collection.ID, Api: api, Path: folderPath})
+				}
+				walk(folder.Folders, folderPath)
+			}
+		}
+		walk(collection.Folders, collection.Name)
+	}
+	return entries
+}
+
+// searchFinder ranks entries against a space separated query. Every term has
+// to match the name, method and URL, location or description of an entry.
+func searchFinder(entries []finderEntry, query string) []finderEntry {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return entries
+	}
+
+	var results []finderEntry
+	for _, entry := range entries {
+		total := 0
+		matched := true
+		for _, term := range terms {
+			score, ok := entryScore(entry, term)
+			if !ok {
+				matched = false
+				break
+			}
+			total += score
+		}
+		if matched {
+			entry.score = total
+			results = append(results, entry)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	return results
+}
+
+// searchRequests is searchFinder backed by the store's full-text index where
+// it has one: indexed matches come first in their ranked order, then any
+// fuzzy matches the index missed.
+func searchRequests(store Store, entries []finderEntry, query string) []finderEntry {
+	fuzzy := searchFinder(entries, query)
+	history, ok := store.(HistoryStore)
+	if !ok || strings.TrimSpace(query) == "" {
+		return fuzzy
+	}
+	apis, err := history.SearchRequests(query, 50)
+	if err != nil || len(apis) == 0 {
+		return fuzzy
+	}
+
+	byID := map[string]finderEntry{}
+	for _, entry := range entries {
+		byID[entry.Api.ID] = entry
+	}
+	var results []finderEntry
+	seen := map[string]bool{}
+	for _, api := range apis {
+		if entry, ok := byID[api.ID]; ok && !seen[api.ID] {
+			results = append(results, entry)
+			seen[api.ID] = true
+		}
+	}
+	for _, entry := range fuzzy {
+		if !seen[entry.Api.ID] {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
+func entryScore(entry finderEntry, term string) (int, bool) {
+	best, found := 0, false
+	fields := []struct {
+		text  string
+		bonus int
+	}{
+		{entry.Api.Name, 20},
+		{entry.Api.Method + " " + entry.Api.Url, 10},
+		{entry.Path, 0},
+		{entry.Api.Description, -20},
+	}
+	for _, field := range fields {
+		if score, ok := fuzzyScore(term, field.text); ok && (!found || score+field.bonus > best) {
+			best, found = score+field.bonus, true
+		}
+	}
+	return best, found
+}
+
+// fuzzyScore matches pattern as a subsequence of text, rewarding consecutive
+// characters and matches at the start of words.
+func fuzzyScore(pattern string, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	runes := []rune(strings.ToLower(text))
+	pat := []rune(pattern)
+
+	score, p, streak := 0, 0, 0
+	for i, r := range runes {
+		if p == len(pat) {
+			break
+		}
+		if r != pat[p] {
+			streak = 0
+			continue
+		}
+		score++
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			score += 8
+		}
+		streak++
+		score += streak * 2
+		p++
+	}
+	if p < len(pat) {
+		return 0, false
+	}
+	return score - len(runes)/10, true
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// treeRow is one visible line of the collection tree: either a folder or a request.
+type treeRow struct {
+	Depth    int
+	Folder   *Folder
+	Api      *Api
+	ParentID string
+}
+
+func collectionRows(collection *Collection, expanded map[string]bool) []treeRow {
+	var rows []treeRow
+	appendFolderRows(&rows, collection.Folders, collection.Requests, "", 0, expanded)
+	return rows
+}
+
+func appendFolderRows(rows *[]treeRow, folders []Folder, apis []Api, parentID string, depth int, expanded map[string]bool) {
+	for i := range folders {
+		folder := &folders[i]
+		*rows = append(*rows, treeRow{Depth: depth, Folder: folder, ParentID: parentID})
+		if expanded[folder.ID] {
+			appendFolderRows(rows, folder.Folders, folder.Requests, folder.ID, depth+1, expanded)
+		}
+	}
+	for i := range apis {
+		*rows = append(*rows, treeRow{Depth: depth, Api: &apis[i], ParentID: parentID})
+	}
+}
+
+func rowIndex(rows []treeRow, id string) int {
+	for i, row := range rows {
+		if row.Folder != nil && row.Folder.ID == id || row.Api != nil && row.Api.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// allApis lists every request in the collection, including those inside folders.
+func allApis(collection Collection) []Api {
+	apis := append([]Api{}, collection.Requests...)
+	var walk func(folders []Folder)
+	walk = func(folders []Folder) {
+		for _, folder := range folders {
+			apis = append(apis, folder.Requests...)
+			walk(folder.Folders)
+		}
+	}
+	walk(collection.Folders)
+	return apis
+}
+
+func findFolder(folders []Folder, folderID string) *Folder {
+	for i := range folders {
+		if folders[i].ID == folderID {
+			return &folders[i]
+		}
+		if found := findFolder(folders[i].Folders, folderID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// folderPath returns the folders enclosing the request, outermost first.
+func folderPath(folders []Folder, apiID string) ([]*Folder, bool) {
+	for i := range folders {
+		folder := &folders[i]
+		if indexByID(folder.Requests, apiID) >= 0 {
+			return []*Folder{folder}, true
+		}
+		if path, ok := folderPath(folder.Folders, apiID); ok {
+			return append([]*Folder{folder}, path...), true
+		}
+	}
+	return nil, false
+}
+
+// resolveRequest applies the headers, auth and variables inherited from the
+// collection and enclosing folders. Nearer definitions win over outer ones.
+func resolveRequest(collection Collection, api Api) (Api, []LocalVariable) {
+	path, _ := folderPath(collection.Folders, api.ID)
+
+	variables := enabledItems(collection.LocalVariables)
+	var headers []Header
+	var auth *Auth
+	for _, folder := range path {
+		variables = mergeVariables(variables, enabledItems(folder.LocalVariables))
+		headers = mergeHeaders(headers, enabledItems(folder.Headers))
+		if folder.Auth != nil {
+			auth = folder.Auth
+		}
+	}
+	if header, ok := authHeader(auth); ok {
+		headers = mergeHeaders(headers, []Header{header})
+	}
+
+	resolved := api
+	resolved.Headers = mergeHeaders(headers, enabledItems(api.Headers))
+	return resolved, variables
+}
+
+type toggleable interface {
+	isEnabled() bool
+}
+
+// enabledItems drops the items that have been switched off.
+func enabledItems[T toggleable](items []T) []T {
+	var enabled []T
+	for _, item := range items {
+		if item.isEnabled() {
+			enabled = append(enabled, item)
+		}
+	}
+	return enabled
+}
+
+func mergeVariables(base []LocalVariable, overrides []LocalVariable) []LocalVariable {
+	merged := append([]LocalVariable{}, base...)
+	for _, override := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].Key == override.Key {
+				merged[i] = override
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+func mergeHeaders(base []Header, overrides []Header) []Header {
+	merged := append([]Header{}, base...)
+	for _, override := range overrides {
+		replaced := false
+		for i := range merged {
+			if strings.EqualFold(merged[i].Key, override.Key) {
+				merged[i] = override
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+func authHeader(auth *Auth) (Header, bool) {
+	if auth == nil {
+		return Header{}, false
+	}
+	switch auth.Type {
+	case "bearer":
+		return Header{Key: "Authorization", Value: "Bearer " + auth.Token}, true
+	case "basic":
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		return Header{Key: "Authorization", Value: "Basic " + credentials}, true
+	}
+	return Header{}, false
+}
+
+// parseAuthInput accepts "bearer TOKEN", "basic USER:PASS" or "none".
+func parseAuthInput(input string) (*Auth, error) {
+	parts := strings.SplitN(strings.TrimSpace(input), " ", 2)
+	switch strings.ToLower(parts[0]) {
+	case "", "none":
+		return nil, nil
+	case "bearer":
+		if len(parts) < 2 || parts[1] == "" {
+			return nil, fmt.Errorf("expected 'bearer TOKEN'")
+		}
+		return &Auth{Type: "bearer", Token: parts[1]}, nil
+	case "basic":
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("expected 'basic USER:PASS'")
+		}
+		user, pass, _ := strings.Cut(parts[1], ":")
+		return &Auth{Type: "basic", Username: user, Password: pass}, nil
+	}
+	return nil, fmt.Errorf("unknown auth type %q: expected bearer, basic or none", parts[0])
+}
+
+func formatAuth(auth *Auth) string {
+	if auth == nil {
+		return "none"
+	}
+	switch auth.Type {
+	case "bearer":
+		return "bearer " + auth.Token
+	case "basic":
+		return "basic " + auth.Username + ":" + auth.Password
+	}
+	return auth.Type
+}
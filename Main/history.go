@@ -0,0 +1,81 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyLimit is how many entries the history page lists.
+const historyLimit = 200
+
+// openHistory shows the response history, newest first. Only stores that
+// keep one have it.
+func (m *model) openHistory() tea.Cmd {
+	if _, ok := m.store.(HistoryStore); !ok {
+		return showErrorCommand("Response history needs the SQLite storage, start with -db")
+	}
+	m.historyReturn = m.CurrentPage
+	m.CurrentPage = HistoryPage
+	m.historyInput.SetValue("")
+	m.searchHistory()
+	return m.historyInput.Focus()
+}
+
+func (m *model) searchHistory() {
+	m.pointer = 0
+	m.historyOpen = false
+	entries, err := m.store.(HistoryStore).SearchHistory(m.historyInput.Value(), historyLimit)
+	if err != nil {
+		m.historyError = err.Error()
+		return
+	}
+	m.historyEntries = entries
+	m.historyError = ""
+}
+
+func UpdateHistoryPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			if m.historyOpen {
+				m.historyOpen = false
+				return m, nil
+			}
+			m.historyInput.Blur()
+			m.CurrentPage = m.historyReturn
+			m.pointer = 0
+			if m.historyReturn == HomePage {
+				m.pointer = m.collectionIndex
+			}
+			return m, nil
+		case "up", "ctrl+k":
+			if m.pointer > 0 {
+				m.pointer--
+			}
+			return m, nil
+		case "down", "ctrl+j":
+			if m.pointer < len(m.historyEntries)-1 {
+				m.pointer++
+			}
+			return m, nil
+		case "enter":
+			if len(m.historyEntries) > 0 {
+				m.historyOpen = !m.historyOpen
+			}
+			return m, nil
+		}
+
+		before := m.historyInput.Value()
+		m.historyInput, cmd = m.historyInput.Update(msg)
+		if m.historyInput.Value() != before {
+			m.searchHistory()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// historyPreviewLines is how much of a recorded body the history page shows.
+const historyPreviewLines = 30
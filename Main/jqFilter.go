@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/itchyny/gojq"
+)
+
+// jqTimeout stops filters like `repeat(.)` from hanging the UI.
+const jqTimeout = 2 * time.Second
+
+// applyJQ runs a jq expression against a JSON body and returns every result
+// pretty-printed. It stops when ctx is done.
+func applyJQ(ctx context.Context, body string, expression string) ([]string, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var input any
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("response body is not JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("response body is not JSON: unexpected data after the value")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, jqTimeout)
+	defer cancel()
+
+	var results []string
+	iter := query.RunWithContext(ctx, jqNumbers(input))
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := value.(error); ok {
+			if halt, ok := err.(*gojq.HaltError); ok && halt.Value() == nil {
+				break
+			}
+			return nil, err
+		}
+		var formatted bytes.Buffer
+		encoder := json.NewEncoder(&formatted)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			return nil, err
+		}
+		results = append(results, strings.TrimSuffix(formatted.String(), "\n"))
+	}
+	return results, nil
+}
+
+// jqNumbers replaces the json.Numbers left by UseNumber with the number
+// types gojq works on, so integers too large for a float64 keep every digit.
+func jqNumbers(value any) any {
+	switch value := value.(type) {
+	case json.Number:
+		if n, err := value.Int64(); err == nil && int64(int(n)) == n {
+			return int(n)
+		}
+		if n, ok := new(big.Int).SetString(value.String(), 10); ok {
+			return n
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]any:
+		for key, item := range value {
+			value[key] = jqNumbers(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = jqNumbers(item)
+		}
+	}
+	return value
+}
+
+type filterResultMsg struct {
+	seq    int
+	output []string
+	err    error
+}
+
+// updateFilter re-runs the response filter after the expression or the
+// response changed. The filter runs in the background; a run still going is
+// cancelled and its result ignored. A broken expression keeps the last good
+// output so the body does not flicker while typing.
+func (m *model) updateFilter() tea.Cmd {
+	if m.cancelFilter != nil {
+		m.cancelFilter()
+		m.cancelFilter = nil
+	}
+	m.filterSeq++
+	expression := strings.TrimSpace(m.filterInput.Value())
+	if expression == "" {
+		m.filterOutput = nil
+		m.filterError = ""
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelFilter = cancel
+	seq, body := m.filterSeq, m.apiResponse.Body
+	return func() tea.Msg {
+		output, err := applyJQ(ctx, body, expression)
+		return filterResultMsg{seq: seq, output: output, err: err}
+	}
+}
+
+// applyFilterResult shows the output of the latest filter run.
+func (m *model) applyFilterResult(msg filterResultMsg) {
+	if msg.seq != m.filterSeq {
+		return
+	}
+	m.cancelFilter = nil
+	if msg.err != nil {
+		m.filterError = msg.err.Error()
+		return
+	}
+	m.filterOutput = msg.output
+	m.filterError = ""
+}
+
+func (m model) filterActive() bool {
+	return strings.TrimSpace(m.filterInput.Value()) != ""
+}
+
+// responseBody is the body as currently shown: filtered when a jq filter is
+// set.
+func (m model) responseBody() string {
+	if m.filterActive() {
+		return strings.Join(m.filterOutput, "\n")
+	}
+	return m.apiResponse.Body
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyJQ(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		expression string
+		want       []string
+	}{
+		{"html kept", `{"html":"<a href=\"x\">&amp;</a>"}`, ".html", []string{`"<a href=\"x\">&amp;</a>"`}},
+		{"large integer", `{"id":12345678901234567890123}`, ".id", []string{"12345678901234567890123"}},
+		{"int64 id", `{"id":9007199254740993}`, ".id", []string{"9007199254740993"}},
+		{"arithmetic", `{"a":1.5,"b":2}`, ".a + .b", []string{"3.5"}},
+		{"indented", `{"a":[1,2]}`, ".", []string{"{\n  \"a\": [\n    1,\n    2\n  ]\n}"}},
+		{"several results", `[{"id":1},{"id":2}]`, ".[].id", []string{"1", "2"}},
+		{"halt", `1`, "halt", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyJQ(context.Background(), tt.body, tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyJQ = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyJQErrors(t *testing.T) {
+	for _, tt := range []struct{ body, expression string }{
+		{`{"a":1}`, ".a |"},
+		{`not json`, "."},
+		{`{"a":1} {"b":2}`, "."},
+	} {
+		if _, err := applyJQ(context.Background(), tt.body, tt.expression); err == nil {
+			t.Errorf("applyJQ(%s, %s) gave no error", tt.body, tt.expression)
+		}
+	}
+}
+
+func TestApplyJQCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if _, err := applyJQ(ctx, `1`, "repeat(.)"); err == nil {
+		t.Error("cancelled filter gave no error")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("cancelled filter kept running")
+	}
+}
+
+func TestUpdateFilterIgnoresStaleResults(t *testing.T) {
+	m := NewModel(NewMemoryStore(Storage{Version: storageVersion}), Storage{Version: storageVersion})
+	m.apiResponse.Body = `{"a":1,"b":2}`
+
+	m.filterInput.SetValue(".a")
+	first := m.updateFilter()
+	m.filterInput.SetValue(".b")
+	second := m.updateFilter()
+
+	m.applyFilterResult(second().(filterResultMsg))
+	m.applyFilterResult(first().(filterResultMsg))
+	if !reflect.DeepEqual(m.filterOutput, []string{"2"}) || m.filterError != "" {
+		t.Errorf("filterOutput = %q, error %q", m.filterOutput, m.filterError)
+	}
+
+	m.filterInput.SetValue("")
+	if cmd := m.updateFilter(); cmd != nil || m.filterOutput != nil {
+		t.Error("clearing the filter should not run it")
+	}
+}
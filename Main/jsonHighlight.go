@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FormatJSON pretty-prints a JSON body, keeping key order, and colours each
+// token by kind. Bodies that are not valid JSON are returned unchanged.
+func FormatJSON(body string) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(body), "", "  "); err != nil {
+		return body
+	}
+	return highlightJSON(indented.String())
+}
+
+// highlightJSON colours already valid JSON in a single pass. A string is a
+// key when the next character after it, ignoring whitespace, is a colon.
+// Escape codes are worked out once per style, and neighbouring tokens of the
+// same style on a line share them, so large bodies stay fast.
+func highlightJSON(text string) string {
+	var (
+		key         = ansiCodes(jsonKeyStyle)
+		str         = ansiCodes(jsonStringStyle)
+		number      = ansiCodes(jsonNumberStyle)
+		boolean     = ansiCodes(jsonBoolStyle)
+		null        = ansiCodes(jsonNullStyle)
+		punctuation = ansiCodes(jsonPunctuationStyle)
+	)
+	w := styledWriter{}
+	w.b.Grow(len(text) * 2)
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := jsonStringEnd(text, i)
+			style := str
+			if jsonNextByte(text, end) == ':' {
+				style = key
+			}
+			w.write(style, text[i:end])
+			i = end
+		case c == '-' || c >= '0' && c <= '9':
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789+-.eE", text[end]) >= 0 {
+				end++
+			}
+			w.write(number, text[i:end])
+			i = end
+		case strings.HasPrefix(text[i:], "true"):
+			w.write(boolean, "true")
+			i += 4
+		case strings.HasPrefix(text[i:], "false"):
+			w.write(boolean, "false")
+			i += 5
+		case strings.HasPrefix(text[i:], "null"):
+			w.write(null, "null")
+			i += 4
+		case strings.IndexByte("{}[],:", c) >= 0:
+			w.write(punctuation, text[i:i+1])
+			i++
+		case c == '\n':
+			w.end()
+			w.b.WriteByte(c)
+			i++
+		default:
+			w.b.WriteByte(c)
+			i++
+		}
+	}
+	w.end()
+	return w.b.String()
+}
+
+// ansiStyle is the escape code that starts a style and the one that ends it.
+type ansiStyle struct {
+	open  string
+	close string
+}
+
+// ansiCodes renders a placeholder to find the codes lipgloss would wrap text
+// in. Without colour support both are empty.
+func ansiCodes(style lipgloss.Style) ansiStyle {
+	open, close, _ := strings.Cut(style.Render("x"), "x")
+	return ansiStyle{open: open, close: close}
+}
+
+// styledWriter keeps a style open across tokens until a different style or
+// the end of the line.
+type styledWriter struct {
+	b       strings.Builder
+	current ansiStyle
+	open    bool
+}
+
+func (w *styledWriter) write(style ansiStyle, text string) {
+	if !w.open || style != w.current {
+		w.end()
+		w.b.WriteString(style.open)
+		w.current, w.open = style, true
+	}
+	w.b.WriteString(text)
+}
+
+func (w *styledWriter) end() {
+	if w.open {
+		w.b.WriteString(w.current.close)
+		w.open = false
+	}
+}
+
+// jsonStringEnd returns the index just past the closing quote of the string
+// starting at start, skipping escaped characters.
+func jsonStringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+func jsonNextByte(text string, from int) byte {
+	for i := from; i < len(text); i++ {
+		switch text[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return text[i]
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+// withColours makes lipgloss emit ANSI codes, which it skips when the tests
+// are not run in a terminal.
+func withColours(t *testing.T) {
+	t.Helper()
+	old := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(old) })
+}
+
+func TestFormatJSONKeepsText(t *testing.T) {
+	withColours(t)
+	bodies := []string{
+		`{"a":"b"}`,
+		`{"time":"12:30","url":"http://x/?a=1"}`,
+		`{"quote":"say \"hi\": now","slash":"a\\"}`,
+		`{"":"","empty":{},"list":[]}`,
+		`[[1,[2,[3,[]]]],[{"a":[{}]}]]`,
+		`{"n":-1.5e+10,"m":2E-3,"z":0,"big":12345678901234567890}`,
+		`{"t":true,"f":false,"n":null,"s":"true"}`,
+		`{"unicode":"café ☕","tab":"a\tb"}`,
+		`"just a string"`,
+		`42`,
+	}
+	for _, body := range bodies {
+		var want bytes.Buffer
+		if err := json.Indent(&want, []byte(body), "", "  "); err != nil {
+			t.Fatalf("bad fixture %s: %v", body, err)
+		}
+		got := FormatJSON(body)
+		if !strings.Contains(got, "\x1b[") {
+			t.Errorf("FormatJSON(%s) has no colours", body)
+		}
+		if stripped := ansi.Strip(got); stripped != want.String() {
+			t.Errorf("FormatJSON(%s) text =\n%s\nwant\n%s", body, stripped, want.String())
+		}
+	}
+}
+
+func TestFormatJSONInvalidUnchanged(t *testing.T) {
+	for _, body := range []string{``, `{"a":`, `not json`, `{"a":1}}`} {
+		if got := FormatJSON(body); got != body {
+			t.Errorf("FormatJSON(%q) = %q, want it unchanged", body, got)
+		}
+	}
+}
+
+func TestHighlightJSONTokenKinds(t *testing.T) {
+	withColours(t)
+	key, str := ansiCodes(jsonKeyStyle), ansiCodes(jsonStringStyle)
+	number, boolean, null := ansiCodes(jsonNumberStyle), ansiCodes(jsonBoolStyle), ansiCodes(jsonNullStyle)
+
+	tests := []struct {
+		body  string
+		token string
+		style ansiStyle
+	}{
+		{`{"a\":b": 1}`, `"a\":b"`, key},
+		{`{"k": "x\": y"}`, `"x\": y"`, str},
+		{`{"k": "a:b"}`, `"a:b"`, str},
+		{`{"": ""}`, `"": `, ansiStyle{}},
+		{`["k", "v"]`, `"k"`, str},
+		{`{"k": -1.5e+10}`, `-1.5e+10`, number},
+		{`{"k": 2E-3}`, `2E-3`, number},
+		{`{"k": false}`, `false`, boolean},
+		{`{"k": null}`, `null`, null},
+		{`{"k": "null"}`, `"null"`, str},
+	}
+	for _, tt := range tests {
+		var indented bytes.Buffer
+		json.Indent(&indented, []byte(tt.body), "", "  ")
+		got := highlightJSON(indented.String())
+		if tt.style == (ansiStyle{}) {
+			// The empty key must be coloured as a key, the empty value as a
+			// string, even though the text is the same.
+			if !strings.Contains(got, key.open+`""`) || !strings.Contains(got, str.open+`""`) {
+				t.Errorf("highlightJSON(%s) = %q", tt.body, got)
+			}
+			continue
+		}
+		if !strings.Contains(got, tt.style.open+tt.token) {
+			t.Errorf("highlightJSON(%s): %s not styled as expected in %q", tt.body, tt.token, got)
+		}
+	}
+}
+
+func TestHighlightJSONClosesStylesPerLine(t *testing.T) {
+	withColours(t)
+	got := FormatJSON(`{"a":[1,2],"b":{"c":null}}`)
+	for _, line := range strings.Split(got, "\n") {
+		if strings.Count(line, "\x1b[0m") == 0 && strings.Contains(line, "\x1b[") {
+			t.Errorf("line leaves a style open: %q", line)
+		}
+	}
+}
+
+func largeJSON(size int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; b.Len() < size; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id":%d,"name":"item %d","price":%d.5e2,"tags":["a","b:c"],"ok":true,"note":null}`, i, i, i)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func BenchmarkFormatJSON(b *testing.B) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	body := largeJSON(3 << 20)
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		FormatJSON(body)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonString
+	jsonNumber
+	jsonBool
+	jsonNull
+)
+
+// jsonNode is one value in a response body. Object keys keep the order they
+// had in the body.
+type jsonNode struct {
+	Kind     jsonKind
+	Key      string
+	Index    int
+	Value    string
+	Children []*jsonNode
+	Parent   *jsonNode
+	Expanded bool
+	Depth    int
+}
+
+// jsonTree is the collapsible view of a response body on the ApiPage.
+type jsonTree struct {
+	Root   *jsonNode
+	Rows   []*jsonNode
+	Cursor int
+	Offset int
+}
+
+func parseJSONTree(body string) (*jsonTree, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	root, err := readJSONNode(decoder, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("response body is not JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("response body is not JSON: unexpected data after the first value")
+	}
+	root.Expanded = true
+	tree := &jsonTree{Root: root}
+	tree.refresh()
+	return tree, nil
+}
+
+func readJSONNode(decoder *json.Decoder, parent *jsonNode, depth int) (*jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &jsonNode{Parent: parent, Depth: depth, Index: -1}
+	switch value := token.(type) {
+	case json.Delim:
+		node.Kind = jsonArray
+		if value == '{' {
+			node.Kind = jsonObject
+		}
+		for decoder.More() {
+			key := ""
+			if node.Kind == jsonObject {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyToken.(string)
+			}
+			child, err := readJSONNode(decoder, node, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			child.Key = key
+			if node.Kind == jsonArray {
+				child.Index = len(node.Children)
+			}
+			node.Children = append(node.Children, child)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind, node.Value = jsonString, value
+	case json.Number:
+		node.Kind, node.Value = jsonNumber, value.String()
+	case bool:
+		node.Kind, node.Value = jsonBool, strconv.FormatBool(value)
+	default:
+		node.Kind, node.Value = jsonNull, "null"
+	}
+	return node, nil
+}
+
+func (n *jsonNode) isContainer() bool {
+	return n.Kind == jsonObject || n.Kind == jsonArray
+}
+
+var jsonIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// path is the JSONPath of the node, e.g. $.items[2].name.
+func (n *jsonNode) path() string {
+	if n.Parent == nil {
+		return "$"
+	}
+	parent := n.Parent.path()
+	if n.Parent.Kind == jsonArray {
+		return parent + "[" + strconv.Itoa(n.Index) + "]"
+	}
+	if jsonIdentifier.MatchString(n.Key) {
+		return parent + "." + n.Key
+	}
+	return parent + "[" + strconv.Quote(n.Key) + "]"
+}
+
+// text is the value as it is copied or saved: strings without quotes,
+// everything else as compact JSON.
+func (n *jsonNode) text() string {
+	if n.Kind == jsonString {
+		return n.Value
+	}
+	var b strings.Builder
+	writeJSONNode(&b, n)
+	return b.String()
+}
+
+func writeJSONNode(b *strings.Builder, n *jsonNode) {
+	switch n.Kind {
+	case jsonObject:
+		b.WriteString("{")
+		for i, child := range n.Children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			key, _ := json.Marshal(child.Key)
+			b.Write(key)
+			b.WriteString(":")
+			writeJSONNode(b, child)
+		}
+		b.WriteString("}")
+	case jsonArray:
+		b.WriteString("[")
+		for i, child := range n.Children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			writeJSONNode(b, child)
+		}
+		b.WriteString("]")
+	case jsonString:
+		value, _ := json.Marshal(n.Value)
+		b.Write(value)
+	default:
+		b.WriteString(n.Value)
+	}
+}
+
+// variableName suggests a variable name for the node: its key, or the
+// parent's key with the index for array items.
+func (n *jsonNode) variableName() string {
+	if n.Parent == nil {
+		return "response"
+	}
+	if n.Parent.Kind == jsonArray {
+		return n.Parent.variableName() + "_" + strconv.Itoa(n.Index)
+	}
+	return n.Key
+}
+
+// summary describes a container without its children.
+func (n *jsonNode) summary() string {
+	switch n.Kind {
+	case jsonObject:
+		return fmt.Sprintf("{…} %d keys", len(n.Children))
+	case jsonArray:
+		return fmt.Sprintf("[…] %d items", len(n.Children))
+	}
+	return n.Value
+}
+
+// refresh rebuilds the visible rows after nodes were expanded or collapsed.
+func (t *jsonTree) refresh() {
+	t.Rows = t.Rows[:0]
+	var walk func(node *jsonNode)
+	walk = func(node *jsonNode) {
+		t.Rows = append(t.Rows, node)
+		if node.Expanded {
+			for _, child := range node.Children {
+				walk(child)
+			}
+		}
+	}
+	walk(t.Root)
+	t.Cursor = min(t.Cursor, len(t.Rows)-1)
+}
+
+func (t *jsonTree) current() *jsonNode {
+	return t.Rows[t.Cursor]
+}
+
+func (t *jsonTree) move(delta int) {
+	t.Cursor = min(max(t.Cursor+delta, 0), len(t.Rows)-1)
+}
+
+func (t *jsonTree) expand() {
+	node := t.current()
+	if node.isContainer() {
+		node.Expanded = true
+		t.refresh()
+	}
+}
+
+// collapse closes the current node, or jumps to its parent when it is
+// already closed.
+func (t *jsonTree) collapse() {
+	node := t.current()
+	if node.isContainer() && node.Expanded {
+		node.Expanded = false
+		t.refresh()
+		return
+	}
+	if node.Parent != nil {
+		t.selectNode(node.Parent)
+	}
+}
+
+func (t *jsonTree) toggle() {
+	node := t.current()
+	if node.isContainer() {
+		node.Expanded = !node.Expanded
+		t.refresh()
+	}
+}
+
+// setExpanded opens or closes the current node and everything below it.
+func (t *jsonTree) setExpanded(expanded bool) {
+	var walk func(node *jsonNode)
+	walk = func(node *jsonNode) {
+		if node.isContainer() {
+			node.Expanded = expanded
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(t.current())
+	t.current().Expanded = expanded || t.current() == t.Root
+	t.refresh()
+}
+
+func (t *jsonTree) selectNode(node *jsonNode) {
+	for i, row := range t.Rows {
+		if row == node {
+			t.Cursor = i
+			return
+		}
+	}
+}
+
+// scroll keeps the cursor inside a window of height rows.
+func (t *jsonTree) scroll(height int) {
+	height = max(height, 1)
+	if t.Cursor < t.Offset {
+		t.Offset = t.Cursor
+	}
+	if t.Cursor >= t.Offset+height {
+		t.Offset = t.Cursor - height + 1
+	}
+	t.Offset = min(t.Offset, max(len(t.Rows)-height, 0))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localStateFileName holds what belongs to this machine rather than to the
+// shared workspace, so it never ends up in a commit: the cookie jars and
+// proxy credentials.
+const localStateFileName = ".local.json"
+
+type localState struct {
+	// Cookies maps a collection ID to its jar.
+	Cookies map[string][]Cookie `json:"cookies,omitempty"`
+	// ProxyCredentials maps a collection ID, or "" for the global proxy, to
+	// the proxy username and password.
+	ProxyCredentials map[string]proxyCredentials `json:"proxyCredentials,omitempty"`
+}
+
+type proxyCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func takeCredentials(proxy *ProxySettings) (proxyCredentials, bool) {
+	credentials := proxyCredentials{Username: proxy.Username, Password: proxy.Password}
+	proxy.Username, proxy.Password = "", ""
+	return credentials, credentials != proxyCredentials{}
+}
+
+// localStatePath is next to the data file, or inside the data directory.
+// Either way a .gitignore beside it keeps it untracked.
+func localStatePath() string {
+	if dataDir != "" {
+		return filepath.Join(dataDir, localStateFileName)
+	}
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + ".local" + ext
+}
+
+func readLocalState() (localState, error) {
+	var state localState
+	data, err := os.ReadFile(localStatePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read local state: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return state, fmt.Errorf("failed to parse %s: %w", localStatePath(), err)
+		}
+	}
+	return state, nil
+}
+
+func writeLocalState(state localState) error {
+	path := localStatePath()
+	if len(state.Cookies) == 0 && len(state.ProxyCredentials) == 0 {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	if err := ignoreInDir(filepath.Dir(path), filepath.Base(path)); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode local state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write local state: %w", err)
+	}
+	return nil
+}
+
+// ignoreInDir adds name to the .gitignore in dir.
+func ignoreInDir(dir string, name string) error {
+	path := filepath.Join(dir, ".gitignore")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == name || strings.TrimSpace(line) == "/"+name {
+			return nil
+		}
+	}
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		existing = append(existing, '\n')
+	}
+	if err := os.WriteFile(path, append(existing, "/"+name+"\n"...), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// splitLocalState takes the local parts out of storage before it is saved.
+func splitLocalState(storage *Storage) localState {
+	state := localState{Cookies: map[string][]Cookie{}, ProxyCredentials: map[string]proxyCredentials{}}
+	if credentials, ok := takeCredentials(&storage.Settings.Proxy); ok {
+		state.ProxyCredentials[""] = credentials
+	}
+	collections := make([]Collection, len(storage.Collections))
+	for i, collection := range storage.Collections {
+		if len(collection.Cookies) > 0 {
+			state.Cookies[collection.ID] = collection.Cookies
+		}
+		collection.Cookies = nil
+		if credentials, ok := takeCredentials(&collection.Settings.Proxy); ok {
+			state.ProxyCredentials[collection.ID] = credentials
+		}
+		collections[i] = collection
+	}
+	storage.Collections = collections
+	return state
+}
+
+// mergeLocalState puts the local parts back into a loaded storage. It
+// reports whether the workspace itself still held any, as files written
+// before they moved out did.
+func mergeLocalState(storage *Storage, state localState) bool {
+	tracked := mergeCredentials(&storage.Settings.Proxy, state.ProxyCredentials, "")
+	for i := range storage.Collections {
+		collection := &storage.Collections[i]
+		if len(collection.Cookies) > 0 {
+			tracked = true
+		}
+		if cookies, ok := state.Cookies[collection.ID]; ok {
+			collection.Cookies = cookies
+		}
+		if mergeCredentials(&collection.Settings.Proxy, state.ProxyCredentials, collection.ID) {
+			tracked = true
+		}
+	}
+	return tracked
+}
+
+func mergeCredentials(proxy *ProxySettings, saved map[string]proxyCredentials, key string) bool {
+	tracked := proxy.Username != "" || proxy.Password != ""
+	if credentials, ok := saved[key]; ok {
+		proxy.Username, proxy.Password = credentials.Username, credentials.Password
+	}
+	return tracked
+}
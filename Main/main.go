@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,11 +25,18 @@ const (
 	LoadingPage
 	ResponsePage
 	VariablesPage
+	DocsPage
+	FinderPage
+	PathParamsPage
+	CookiesPage
+	SettingsPage
+	HistoryPage
 )
 
 type model struct {
 	NewApiInput        textinput.Model
 	NewCollectionInput textinput.Model
+	store              Store
 	storage            Storage
 	SelectedCollection Collection
 	collectionIndex    int
@@ -46,11 +56,43 @@ type model struct {
 	editingCurrentApi textinput.Model
 	editing           bool
 
+	NewFolderInput textinput.Model
+	editingFolder  textinput.Model
+	editingAuth    textinput.Model
+	SelectedFolder Folder
+	expanded       map[string]bool
+
+	// Requests and collections marked with m (move) or c (copy) until pasted with p.
+	clipboardApiID        string
+	clipboardCollectionID string
+	clipboardCopy         bool
+
+	undoStack []change
+	redoStack []change
+
+	// Collection waiting for y/n before it is deleted.
+	confirmDeleteID string
+
+	editingName      textinput.Model
+	descriptionInput textarea.Model
+	docsViewport     viewport.Model
+	docsApiID        string
+	docsReturn       View
+
+	finderInput   textinput.Model
+	finderResults []finderEntry
+	finderReturn  View
+
+	historyInput   textinput.Model
+	historyEntries []HistoryEntry
+	historyOpen    bool
+	historyError   string
+	historyReturn  View
+
 	addHeaderKey   textinput.Model
 	addHeaderValue textinput.Model
 	editingHeader  textinput.Model
 	Headers        []Header
-	ApiIndex       int
 
 	newBodyFieldInput   textinput.Model
 	bodyFiledValueInput textinput.Model
@@ -62,6 +104,14 @@ type model struct {
 	editingQueryParams  textinput.Model
 	QueryParams         []QueryParam
 
+	editingPathParam textinput.Model
+	PathParams       []PathParam
+
+	editingCookie textinput.Model
+
+	editingSetting textinput.Model
+	settingsScope  settingsScope
+
 	Responses             []Response
 	LocalVariables        []LocalVariable
 	VariablesFocus        bool
@@ -71,11 +121,32 @@ type model struct {
 
 	apiResponse ApiResponse
 
+	cancelRequest context.CancelFunc
+	requestEvents chan tea.Msg
+	download      downloadProgressMsg
+	saveInput     textinput.Model
+	saveHeaders   bool
+	saveNotice    string
+
+	jsonTree          *jsonTree
+	treeVariableInput textinput.Model
+
+	filterInput  textinput.Model
+	filterOutput []string
+	filterError  string
+	filterSeq    int
+	cancelFilter context.CancelFunc
+
+	searchInput   textinput.Model
+	searchMatches []searchMatch
+	searchIndex   int
+	searchError   string
+
 	errorMessage string
 	hasError     bool
 }
 
-func NewModel(storage Storage) model {
+func NewModel(store Store, storage Storage) model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter JSON Body here..."
 	ti.CharLimit = 50
@@ -85,6 +156,14 @@ func NewModel(storage Storage) model {
 	ai.Placeholder = "Add New Api..."
 	ai.Width = 50
 
+	folderInput := textinput.New()
+	folderInput.Placeholder = "Add New Folder..."
+	folderInput.Width = 50
+
+	authInput := textinput.New()
+	authInput.Placeholder = "bearer TOKEN | basic USER:PASS | none"
+	authInput.Width = 50
+
 	collInput := textinput.New()
 	collInput.Placeholder = "Add New Collection..."
 	collInput.Width = 50
@@ -121,12 +200,42 @@ func NewModel(storage Storage) model {
 	VariableValue.Placeholder = "Add New Variable Value..."
 	VariableValue.Width = 50
 
+	finderInput := textinput.New()
+	finderInput.Placeholder = "Search requests..."
+	finderInput.Width = 50
+
+	historyInput := textinput.New()
+	historyInput.Placeholder = "Search URLs and response bodies..."
+	historyInput.Width = 50
+
+	filterInput := textinput.New()
+	filterInput.Placeholder = "jq filter, e.g. .items[].id"
+	filterInput.Width = 50
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+	searchInput.Placeholder = "regex"
+	searchInput.Width = 50
+
+	descriptionInput := textarea.New()
+	descriptionInput.Placeholder = "Describe this in markdown..."
+	descriptionInput.ShowLineNumbers = false
+
 	return model{
 		CurrentPage:         HomePage,
 		jsonInput:           ti,
 		viewportReady:       false,
 		NewApiInput:         ai,
 		NewCollectionInput:  collInput,
+		NewFolderInput:      folderInput,
+		editingAuth:         authInput,
+		expanded:            map[string]bool{},
+		descriptionInput:    descriptionInput,
+		finderInput:         finderInput,
+		historyInput:        historyInput,
+		filterInput:         filterInput,
+		searchInput:         searchInput,
+		store:               store,
 		storage:             storage,
 		Collections:         storage.Collections,
 		addHeaderKey:        addHeaderKey,
@@ -145,22 +254,50 @@ func (m model) Init() tea.Cmd {
 }
 
 func main() {
+	flag.StringVar(&fileName, "file", fileName, "JSON data file")
+	flag.StringVar(&dataDir, "dir", "", "store collections as a directory with one file per request")
+	flag.StringVar(&dbPath, "db", "", "store collections and response history in a SQLite database")
+	docsPath := flag.String("docs", "", "write an API reference (.md or .html, - for stdout) and exit")
+	docsCollection := flag.String("collection", "", "collection to document with -docs (default all)")
+	flag.Parse()
+
+	store := NewFileStore()
+	if dbPath != "" {
+		sqlite, err := NewSQLiteStore(dbPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot start application\n")
+			fmt.Fprintf(os.Stderr, "Reason: %v \n", err)
+			os.Exit(1)
+		}
+		defer sqlite.Close()
+		store = sqlite
+	}
 
-	storage, err := ReadFile()
+	storage, err := store.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Cannot start application\n")
 		fmt.Fprintf(os.Stderr, "Reason: %v \n", err)
 		os.Exit(1)
 	}
 
-	m := NewModel(storage)
+	if *docsPath != "" {
+		if err := generateDocs(storage, *docsCollection, *docsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	m := NewModel(store, storage)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
-	watcher, err := watchFile(p)
-	if err != nil {
-		log.Printf("Warning:File watcher failed: %v", err)
-	} else {
-		defer watcher.Close()
+	if dbPath == "" {
+		watcher, err := watchFile(p)
+		if err != nil {
+			log.Printf("Warning:File watcher failed: %v", err)
+		} else {
+			defer watcher.Close()
+		}
 	}
 
 	if err := p.Start(); err != nil {
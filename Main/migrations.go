@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type migration func(doc map[string]interface{}) error
+
+// migrations[i] upgrades a document from version i to version i+1.
+// Append new steps here whenever Storage, Collection or Api change shape.
+var migrations = []migration{
+	migrateV0ToV1,
+	migrateV1ToV2,
+	migrateV2ToV3,
+	migrateV3ToV4,
+	migrateV4ToV5,
+}
+
+var storageVersion = len(migrations)
+
+// migrateStorage upgrades data to storageVersion and reports whether any
+// migration ran, so callers can persist the upgraded document.
+func migrateStorage(data []byte) ([]byte, bool, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > storageVersion {
+		return nil, false, fmt.Errorf("data file version %d is newer than supported version %d", version, storageVersion)
+	}
+	if version == storageVersion {
+		return data, false, nil
+	}
+
+	for v := version; v < storageVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate data file from version %d to %d: %w", v, v+1, err)
+		}
+		doc["version"] = v + 1
+	}
+
+	data, err = json.Marshal(doc)
+	return data, true, err
+}
+
+func documentVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	version, ok := raw.(float64)
+	if !ok || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid data file version: %v", raw)
+	}
+	return int(version), nil
+}
+
+// Version 0 files have no version field and may contain null lists.
+func migrateV0ToV1(doc map[string]interface{}) error {
+	collections, ok := doc["collections"].([]interface{})
+	if !ok {
+		if doc["collections"] != nil {
+			return fmt.Errorf("collections is not a list")
+		}
+		collections = []interface{}{}
+	}
+	for _, c := range collections {
+		collection, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("collection is not an object")
+		}
+		if collection["requests"] == nil {
+			collection["requests"] = []interface{}{}
+		}
+		if collection["localVariables"] == nil {
+			collection["localVariables"] = []interface{}{}
+		}
+	}
+	doc["collections"] = collections
+	return nil
+}
+
+// Version 2 gives every collection, request, header, query param, body field
+// and variable a persistent ID.
+func migrateV1ToV2(doc map[string]interface{}) error {
+	collections, _ := doc["collections"].([]interface{})
+	for _, c := range collections {
+		collection, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("collection is not an object")
+		}
+		assignID(collection)
+		if err := assignIDs(collection["localVariables"]); err != nil {
+			return err
+		}
+		requests, _ := collection["requests"].([]interface{})
+		for _, r := range requests {
+			request, ok := r.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("request is not an object")
+			}
+			assignID(request)
+			for _, key := range []string{"headers", "bodyFields", "queryParams"} {
+				if err := assignIDs(request[key]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func assignID(item map[string]interface{}) {
+	if id, ok := item["id"].(string); !ok || id == "" {
+		item["id"] = newID()
+	}
+}
+
+func assignIDs(list interface{}) error {
+	items, _ := list.([]interface{})
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("list item is not an object")
+		}
+		assignID(item)
+	}
+	return nil
+}
+
+// Version 3 adds nested folders to collections.
+func migrateV2ToV3(doc map[string]interface{}) error {
+	collections, _ := doc["collections"].([]interface{})
+	for _, c := range collections {
+		collection, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("collection is not an object")
+		}
+		if collection["folders"] == nil {
+			collection["folders"] = []interface{}{}
+		}
+	}
+	return nil
+}
+
+// Version 4 adds an enabled flag to headers, query params, body fields and
+// variables. Everything that existed before stays enabled.
+func migrateV3ToV4(doc map[string]interface{}) error {
+	return walkItems(doc, enableItem)
+}
+
+// Version 5 turns the enabled flag into a disabled one, so an item written
+// without the flag, by hand or by an older version, counts as enabled.
+func migrateV4ToV5(doc map[string]interface{}) error {
+	return walkItems(doc, disableItem)
+}
+
+func enableItem(item map[string]interface{}) {
+	if _, ok := item["enabled"]; !ok {
+		item["enabled"] = true
+	}
+}
+
+func disableItem(item map[string]interface{}) {
+	if enabled, ok := item["enabled"].(bool); ok && !enabled {
+		item["disabled"] = true
+	}
+	delete(item, "enabled")
+}
+
+// walkItems calls fn on every header, query param, body field and variable
+// in the document, including those in folders.
+func walkItems(doc map[string]interface{}, fn func(map[string]interface{})) error {
+	collections, _ := doc["collections"].([]interface{})
+	for _, c := range collections {
+		collection, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("collection is not an object")
+		}
+		if err := walkList(collection["localVariables"], fn); err != nil {
+			return err
+		}
+		if err := walkRequests(collection["requests"], fn); err != nil {
+			return err
+		}
+		if err := walkFolders(collection["folders"], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkFolders(list interface{}, fn func(map[string]interface{})) error {
+	folders, _ := list.([]interface{})
+	for _, f := range folders {
+		folder, ok := f.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("folder is not an object")
+		}
+		if err := walkFolderItems(folder, fn); err != nil {
+			return err
+		}
+		if err := walkRequests(folder["requests"], fn); err != nil {
+			return err
+		}
+		if err := walkFolders(folder["folders"], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkFolderItems(folder map[string]interface{}, fn func(map[string]interface{})) error {
+	for _, key := range []string{"headers", "localVariables"} {
+		if err := walkList(folder[key], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkRequests(list interface{}, fn func(map[string]interface{})) error {
+	requests, _ := list.([]interface{})
+	for _, r := range requests {
+		request, ok := r.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("request is not an object")
+		}
+		if err := walkRequestItems(request, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkRequestItems(request map[string]interface{}, fn func(map[string]interface{})) error {
+	for _, key := range []string{"headers", "bodyFields", "queryParams"} {
+		if err := walkList(request[key], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkList(list interface{}, fn func(map[string]interface{})) error {
+	items, _ := list.([]interface{})
+	for _, i := range items {
+		item, ok := i.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("list item is not an object")
+		}
+		fn(item)
+	}
+	return nil
+}
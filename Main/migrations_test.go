@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useDataFile points the JSON backend at a copy of a fixture for the test.
+func useDataFile(t *testing.T, fixture string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldFile, oldDir := fileName, dataDir
+	fileName, dataDir = path, ""
+	t.Cleanup(func() { fileName, dataDir = oldFile, oldDir })
+	return path
+}
+
+func TestReadFileMigratesFixtures(t *testing.T) {
+	tests := []struct {
+		fixture      string
+		keepsIDs     bool
+		hasFolder    bool
+		limitOn      bool
+		wantMigrated bool
+	}{
+		{"v0.json", false, false, true, true},
+		{"v1.json", false, false, true, true},
+		{"v2.json", true, false, true, true},
+		{"v3.json", true, true, true, true},
+		{"v4.json", true, true, false, true},
+		{"v5.json", true, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			path := useDataFile(t, tt.fixture)
+			before, _ := os.ReadFile(path)
+
+			storage, err := ReadFile()
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if storage.Version != storageVersion {
+				t.Errorf("Version = %d, want %d", storage.Version, storageVersion)
+			}
+			if len(storage.Collections) != 2 {
+				t.Fatalf("got %d collections, want 2", len(storage.Collections))
+			}
+
+			pets, empty := storage.Collections[0], storage.Collections[1]
+			if pets.Name != "Pets" || empty.Name != "Empty" {
+				t.Errorf("names = %q, %q", pets.Name, empty.Name)
+			}
+			if empty.Requests == nil || empty.LocalVariables == nil || empty.Folders == nil {
+				t.Errorf("empty collection has nil lists: %+v", empty)
+			}
+			if len(pets.Requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(pets.Requests))
+			}
+			api := pets.Requests[0]
+			if api.Method != "GET" || api.Url != "https://api.example.com/pets" {
+				t.Errorf("request = %s %s", api.Method, api.Url)
+			}
+			if len(api.Headers) != 1 || api.Headers[0].Key != "Accept" || api.Headers[0].Value != "application/json" || api.Headers[0].Disabled {
+				t.Errorf("headers = %+v", api.Headers)
+			}
+			if len(api.QueryParams) != 1 || api.QueryParams[0].Value != "10" || api.QueryParams[0].Disabled == tt.limitOn {
+				t.Errorf("query params = %+v", api.QueryParams)
+			}
+			if len(pets.LocalVariables) != 1 || pets.LocalVariables[0].Value != "abc" || pets.LocalVariables[0].Disabled {
+				t.Errorf("variables = %+v", pets.LocalVariables)
+			}
+
+			for _, id := range []string{pets.ID, empty.ID, api.ID, api.Headers[0].ID, api.QueryParams[0].ID, pets.LocalVariables[0].ID} {
+				if id == "" {
+					t.Errorf("missing ID in %+v", pets)
+				}
+			}
+			if tt.keepsIDs && (pets.ID != "c-pets" || api.ID != "r-list" || api.Headers[0].ID != "h-accept") {
+				t.Errorf("existing IDs changed: %s %s %s", pets.ID, api.ID, api.Headers[0].ID)
+			}
+			if tt.hasFolder {
+				if len(pets.Folders) != 1 || pets.Folders[0].Name != "Admin" || len(pets.Folders[0].Headers) != 1 || pets.Folders[0].Headers[0].Disabled {
+					t.Errorf("folders = %+v", pets.Folders)
+				}
+			}
+
+			after, _ := os.ReadFile(path)
+			if migrated := string(after) != string(before); migrated != tt.wantMigrated {
+				t.Errorf("file rewritten = %v, want %v", migrated, tt.wantMigrated)
+			}
+			var saved struct{ Version int }
+			if err := json.Unmarshal(after, &saved); err != nil || saved.Version != storageVersion {
+				t.Errorf("saved version = %d (%v), want %d", saved.Version, err, storageVersion)
+			}
+		})
+	}
+}
+
+func TestReadFileRejectsNewerVersion(t *testing.T) {
+	path := useDataFile(t, "v5.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "collections": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile(); err == nil {
+		t.Fatal("ReadFile accepted a newer version")
+	}
+}
+
+func parseDoc(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func firstCollection(doc map[string]interface{}) map[string]interface{} {
+	return doc["collections"].([]interface{})[0].(map[string]interface{})
+}
+
+func TestMigrateV0ToV1(t *testing.T) {
+	doc := parseDoc(t, `{"collections": [{"name": "a", "requests": null}]}`)
+	if err := migrateV0ToV1(doc); err != nil {
+		t.Fatal(err)
+	}
+	collection := firstCollection(doc)
+	if _, ok := collection["requests"].([]interface{}); !ok {
+		t.Errorf("requests = %v, want a list", collection["requests"])
+	}
+	if _, ok := collection["localVariables"].([]interface{}); !ok {
+		t.Errorf("localVariables = %v, want a list", collection["localVariables"])
+	}
+
+	doc = parseDoc(t, `{"collections": null}`)
+	if err := migrateV0ToV1(doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc["collections"].([]interface{}); !ok {
+		t.Errorf("collections = %v, want a list", doc["collections"])
+	}
+
+	if err := migrateV0ToV1(parseDoc(t, `{"collections": 1}`)); err == nil {
+		t.Error("accepted collections that are not a list")
+	}
+}
+
+func TestMigrateV1ToV2(t *testing.T) {
+	doc := parseDoc(t, `{"collections": [{"id": "keep", "localVariables": [{"key": "v"}],
+		"requests": [{"headers": [{"key": "h"}], "bodyFields": [{"key": "b"}], "queryParams": [{"key": "q"}]}]}]}`)
+	if err := migrateV1ToV2(doc); err != nil {
+		t.Fatal(err)
+	}
+	collection := firstCollection(doc)
+	if collection["id"] != "keep" {
+		t.Errorf("existing ID replaced: %v", collection["id"])
+	}
+	request := collection["requests"].([]interface{})[0].(map[string]interface{})
+	items := []interface{}{request, collection["localVariables"].([]interface{})[0]}
+	for _, key := range []string{"headers", "bodyFields", "queryParams"} {
+		items = append(items, request[key].([]interface{})[0])
+	}
+	for _, item := range items {
+		if id, _ := item.(map[string]interface{})["id"].(string); id == "" {
+			t.Errorf("no ID assigned to %v", item)
+		}
+	}
+}
+
+func TestMigrateV2ToV3(t *testing.T) {
+	doc := parseDoc(t, `{"collections": [{"name": "a"}]}`)
+	if err := migrateV2ToV3(doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := firstCollection(doc)["folders"].([]interface{}); !ok {
+		t.Errorf("folders = %v, want a list", firstCollection(doc)["folders"])
+	}
+}
+
+func TestMigrateV3ToV4(t *testing.T) {
+	doc := parseDoc(t, `{"collections": [{"localVariables": [{"key": "v"}],
+		"requests": [{"headers": [{"key": "h", "enabled": false}], "bodyFields": [{"key": "b"}], "queryParams": [{"key": "q"}]}],
+		"folders": [{"headers": [{"key": "fh"}], "localVariables": [], "requests": [],
+			"folders": [{"headers": [], "localVariables": [{"key": "nested"}], "requests": [], "folders": []}]}]}]}`)
+	if err := migrateV3ToV4(doc); err != nil {
+		t.Fatal(err)
+	}
+	collection := firstCollection(doc)
+	request := collection["requests"].([]interface{})[0].(map[string]interface{})
+	folder := collection["folders"].([]interface{})[0].(map[string]interface{})
+	nested := folder["folders"].([]interface{})[0].(map[string]interface{})
+
+	enabled := func(list interface{}) interface{} {
+		return list.([]interface{})[0].(map[string]interface{})["enabled"]
+	}
+	if enabled(request["headers"]) != false {
+		t.Error("an explicitly disabled header was enabled")
+	}
+	for name, list := range map[string]interface{}{
+		"variable":        collection["localVariables"],
+		"body field":      request["bodyFields"],
+		"query param":     request["queryParams"],
+		"folder header":   folder["headers"],
+		"nested variable": nested["localVariables"],
+	} {
+		if enabled(list) != true {
+			t.Errorf("%s not enabled", name)
+		}
+	}
+}
+
+func TestMigrateV4ToV5(t *testing.T) {
+	doc := parseDoc(t, `{"collections": [{"localVariables": [{"key": "v", "enabled": false}],
+		"requests": [{"headers": [{"key": "h"}], "bodyFields": [{"key": "b", "enabled": true}], "queryParams": []}],
+		"folders": [{"headers": [{"key": "fh", "enabled": false}], "localVariables": [], "requests": [], "folders": []}]}]}`)
+	if err := migrateV4ToV5(doc); err != nil {
+		t.Fatal(err)
+	}
+	collection := firstCollection(doc)
+	request := collection["requests"].([]interface{})[0].(map[string]interface{})
+	folder := collection["folders"].([]interface{})[0].(map[string]interface{})
+
+	first := func(list interface{}) map[string]interface{} {
+		return list.([]interface{})[0].(map[string]interface{})
+	}
+	for name, item := range map[string]map[string]interface{}{
+		"variable":      first(collection["localVariables"]),
+		"header":        first(request["headers"]),
+		"body field":    first(request["bodyFields"]),
+		"folder header": first(folder["headers"]),
+	} {
+		if _, ok := item["enabled"]; ok {
+			t.Errorf("%s kept its enabled flag: %v", name, item)
+		}
+	}
+	if first(collection["localVariables"])["disabled"] != true || first(folder["headers"])["disabled"] != true {
+		t.Error("an explicitly disabled item was enabled")
+	}
+	for name, item := range map[string]map[string]interface{}{
+		"header without a flag": first(request["headers"]),
+		"enabled body field":    first(request["bodyFields"]),
+	} {
+		if _, ok := item["disabled"]; ok {
+			t.Errorf("%s was disabled", name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxyNone in ProxySettings.URL sends requests directly, ignoring both the
+// global proxy and the environment.
+const proxyNone = "none"
+
+// ProxySettings route requests through an HTTP, HTTPS or SOCKS5 proxy. An
+// empty URL means a collection uses the global proxy, and the global proxy
+// falls back to the HTTP_PROXY family of environment variables. Username
+// and Password are saved to the local state file, not the workspace.
+type ProxySettings struct {
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// NoProxy lists hosts, domains and CIDRs reached directly, comma
+	// separated as in NO_PROXY.
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// GlobalSettings apply to every collection unless it overrides them.
+type GlobalSettings struct {
+	Proxy ProxySettings `json:"proxy,omitzero"`
+}
+
+// effectiveProxy applies the collection's proxy settings over the global
+// ones field by field: its URL and its credentials each replace the global
+// ones when set, and its no-proxy list adds to the global list. Global
+// credentials are never sent to a proxy only the collection names.
+func effectiveProxy(global ProxySettings, collection ProxySettings) ProxySettings {
+	proxy := global
+	if collection.URL != "" {
+		proxy.URL, proxy.Username, proxy.Password = collection.URL, "", ""
+	}
+	if collection.Username != "" || collection.Password != "" {
+		proxy.Username, proxy.Password = collection.Username, collection.Password
+	}
+	proxy.NoProxy = strings.Trim(global.NoProxy+","+collection.NoProxy, ",")
+	return proxy
+}
+
+// proxyURL parses the proxy address, defaulting to http:// and adding the
+// credentials.
+func proxyURL(settings ProxySettings) (*url.URL, error) {
+	raw := strings.TrimSpace(settings.URL)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", settings.URL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", settings.URL)
+	}
+	if settings.Username != "" {
+		u.User = url.UserPassword(settings.Username, settings.Password)
+	}
+	return u, nil
+}
+
+// proxyFunc returns the transport's Proxy function for the settings. Hosts
+// on the no-proxy list, and localhost, are always reached directly, also
+// when the proxy comes from the environment.
+func proxyFunc(settings ProxySettings) (func(*http.Request) (*url.URL, error), error) {
+	var config httpproxy.Config
+	switch strings.TrimSpace(settings.URL) {
+	case "":
+		config = *httpproxy.FromEnvironment()
+		config.NoProxy = strings.Trim(config.NoProxy+","+settings.NoProxy, ",")
+	case proxyNone:
+		return nil, nil
+	default:
+		u, err := proxyURL(settings)
+		if err != nil {
+			return nil, err
+		}
+		config = httpproxy.Config{HTTPProxy: u.String(), HTTPSProxy: u.String(), NoProxy: settings.NoProxy}
+	}
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// describeProxy shows a proxy without its password.
+func describeProxy(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProxyFuncNoProxy(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy:3128")
+	t.Setenv("NO_PROXY", "skip.example.com")
+
+	tests := []struct {
+		name     string
+		settings ProxySettings
+		url      string
+		want     string
+	}{
+		{"environment", ProxySettings{}, "http://api.example.com/", "http://env-proxy:3128"},
+		{"environment NO_PROXY", ProxySettings{}, "http://skip.example.com/", ""},
+		{"environment with no proxy list", ProxySettings{NoProxy: "internal.example.com"}, "http://internal.example.com/", ""},
+		{"environment list keeps NO_PROXY", ProxySettings{NoProxy: "internal.example.com"}, "http://skip.example.com/", ""},
+		{"environment list other host", ProxySettings{NoProxy: "internal.example.com"}, "http://api.example.com/", "http://env-proxy:3128"},
+		{"configured", ProxySettings{URL: "proxy:8080", NoProxy: ".internal.example.com"}, "http://api.example.com/", "http://proxy:8080"},
+		{"configured no proxy", ProxySettings{URL: "proxy:8080", NoProxy: ".internal.example.com"}, "http://a.internal.example.com/", ""},
+		{"none", ProxySettings{URL: proxyNone}, "http://api.example.com/", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy, err := proxyFunc(tt.settings)
+			must(t, err)
+			if proxy == nil {
+				if tt.want != "" {
+					t.Errorf("no proxy, want %s", tt.want)
+				}
+				return
+			}
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			u, err := proxy(req)
+			must(t, err)
+			got := ""
+			if u != nil {
+				got = u.String()
+			}
+			if got != tt.want {
+				t.Errorf("proxy for %s = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveProxy(t *testing.T) {
+	global := ProxySettings{URL: "global:8080", Username: "me", Password: "global-secret", NoProxy: "a.example.com"}
+	tests := []struct {
+		name       string
+		collection ProxySettings
+		want       ProxySettings
+	}{
+		{"nothing set", ProxySettings{}, global},
+		{"no proxy list only", ProxySettings{NoProxy: "b.example.com"},
+			ProxySettings{URL: "global:8080", Username: "me", Password: "global-secret", NoProxy: "a.example.com,b.example.com"}},
+		{"credentials only", ProxySettings{Username: "pets", Password: "pets-secret"},
+			ProxySettings{URL: "global:8080", Username: "pets", Password: "pets-secret", NoProxy: "a.example.com"}},
+		{"own proxy", ProxySettings{URL: "pets:8080"},
+			ProxySettings{URL: "pets:8080", NoProxy: "a.example.com"}},
+		{"own proxy and credentials", ProxySettings{URL: "pets:8080", Username: "pets", Password: "pets-secret", NoProxy: "b.example.com"},
+			ProxySettings{URL: "pets:8080", Username: "pets", Password: "pets-secret", NoProxy: "a.example.com,b.example.com"}},
+	}
+	for _, tt := range tests {
+		if got := effectiveProxy(global, tt.collection); got != tt.want {
+			t.Errorf("%s: effectiveProxy = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+	if got := effectiveProxy(ProxySettings{}, ProxySettings{NoProxy: "b.example.com"}); got != (ProxySettings{NoProxy: "b.example.com"}) {
+		t.Errorf("without a global proxy = %+v", got)
+	}
+}
+
+func TestProxyCredentialsKeptOutOfWorkspace(t *testing.T) {
+	path := useDataFile(t, "v4.json")
+	storage, err := ReadFile()
+	must(t, err)
+	storage.Settings.Proxy = ProxySettings{URL: "global-proxy:8080", Username: "me", Password: "global-secret"}
+	storage.Collections[0].Settings.Proxy = ProxySettings{URL: "pets-proxy:8080", Username: "pets", Password: "pets-secret"}
+	must(t, WriteFile(storage))
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), `"username"`) {
+		t.Errorf("data file holds proxy credentials: %s", data)
+	}
+	if !strings.Contains(string(data), "pets-proxy:8080") {
+		t.Errorf("data file lost the proxy address: %s", data)
+	}
+
+	loaded, err := ReadFile()
+	must(t, err)
+	if loaded.Settings.Proxy != storage.Settings.Proxy {
+		t.Errorf("global proxy = %+v", loaded.Settings.Proxy)
+	}
+	if got := loaded.Collections[0].Settings.Proxy; got != storage.Collections[0].Settings.Proxy {
+		t.Errorf("collection proxy = %+v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// RedirectHop is one redirect response that was followed, or the last one
+// when redirects are not followed.
+type RedirectHop struct {
+	StatusCode int
+	Status     string
+	URL        string
+	Location   string
+	Headers    http.Header
+}
+
+// redirectRecorder implements http.Client.CheckRedirect for the request
+// settings and keeps every redirect response it sees.
+type redirectRecorder struct {
+	follow       bool
+	maxRedirects int
+	hops         []RedirectHop
+	limitReached bool
+}
+
+func newRedirectRecorder(settings RequestSettings) *redirectRecorder {
+	return &redirectRecorder{follow: settings.followRedirects(), maxRedirects: settings.maxRedirects()}
+}
+
+func (r *redirectRecorder) checkRedirect(req *http.Request, via []*http.Request) error {
+	if resp := req.Response; resp != nil {
+		r.hops = append(r.hops, RedirectHop{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			URL:        resp.Request.URL.String(),
+			Location:   req.URL.String(),
+			Headers:    resp.Header,
+		})
+	}
+	if !r.follow {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > r.maxRedirects {
+		r.limitReached = true
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+// reset forgets the hops of an earlier attempt at the request.
+func (r *redirectRecorder) reset() {
+	r.hops = nil
+	r.limitReached = false
+}
+
+// redirectChain describes the redirects for the response page, each hop
+// with its headers.
+func redirectChain(response ApiResponse) string {
+	if len(response.Redirects) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, hop := range response.Redirects {
+		fmt.Fprintf(&b, "  %d. %s %s\n", i+1, hop.Status, hop.URL)
+		fmt.Fprintf(&b, "     → %s\n", hop.Location)
+		keys := make([]string, 0, len(hop.Headers))
+		for key := range hop.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "       %s : %s\n", key, strings.Join(hop.Headers[key], ", "))
+		}
+	}
+	switch {
+	case response.RedirectLimit:
+		b.WriteString("  Stopped: redirect limit reached\n")
+	case !response.FollowRedirects:
+		b.WriteString("  Not followed: redirects are disabled for this request\n")
+	}
+	return b.String()
+}
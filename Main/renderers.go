@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	csvRowLimit  = 500
+	hexDumpLimit = 1024
+)
+
+// bodyRenderer pretty-prints response bodies of the media types it matches.
+// When Render fails the body is shown as it came.
+type bodyRenderer struct {
+	Name   string
+	Match  func(mediaType string) bool
+	Render func(body string, width int) (string, error)
+}
+
+var bodyRenderers = []bodyRenderer{
+	{Name: "JSON", Match: isJSONType, Render: renderJSONBody},
+	{Name: "XML", Match: isXMLType, Render: renderXMLBody},
+	{Name: "HTML", Match: mediaTypes("text/html", "application/xhtml+xml"), Render: renderHTMLBody},
+	{Name: "YAML", Match: mediaTypes("application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"), Render: renderYAMLBody},
+	{Name: "CSV", Match: mediaTypes("text/csv", "application/csv"), Render: renderCSVBody(',')},
+	{Name: "TSV", Match: mediaTypes("text/tab-separated-values"), Render: renderCSVBody('\t')},
+	{Name: "Binary", Match: isBinaryType, Render: renderBinaryBody},
+}
+
+// renderBody picks a renderer from the response content type. Bodies that are
+// not text always get the binary view so raw bytes never reach the terminal.
+func renderBody(contentType string, body string, width int) string {
+	lastRender.Lock()
+	defer lastRender.Unlock()
+	if lastRender.contentType == contentType && lastRender.width == width && lastRender.body == body && lastRender.output != "" {
+		return lastRender.output
+	}
+	output := renderBodyUncached(contentType, body, width)
+	lastRender.contentType, lastRender.width, lastRender.body, lastRender.output = contentType, width, body, output
+	return output
+}
+
+// lastRender keeps the most recent renderBody result. The response page is
+// rebuilt on every search keystroke, n/N and resize, but the body rarely
+// changes in between.
+var lastRender struct {
+	sync.Mutex
+	contentType string
+	width       int
+	body        string
+	output      string
+}
+
+func renderBodyUncached(contentType string, body string, width int) string {
+	mediaType := responseMediaType(contentType)
+	if looksBinary(body) {
+		mediaType = "application/octet-stream"
+	}
+	for _, renderer := range bodyRenderers {
+		if !renderer.Match(mediaType) {
+			continue
+		}
+		if rendered, err := renderer.Render(body, width); err == nil {
+			return rendered
+		}
+		break
+	}
+	return FormatJSON(body)
+}
+
+func responseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+func mediaTypes(types ...string) func(string) bool {
+	return func(mediaType string) bool {
+		for _, t := range types {
+			if mediaType == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isJSONType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isXMLType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" ||
+		strings.HasSuffix(mediaType, "+xml") && mediaType != "application/xhtml+xml"
+}
+
+func isBinaryType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "audio/") ||
+		strings.HasPrefix(mediaType, "video/") || mediaType == "application/octet-stream" ||
+		mediaType == "application/pdf" || mediaType == "application/zip"
+}
+
+// looksBinary reports whether a body would garble the terminal.
+func looksBinary(body string) bool {
+	sample := body[:min(len(body), 8000)]
+	if strings.ContainsRune(sample, 0) {
+		return true
+	}
+	for len(sample) > 0 {
+		r, size := utf8.DecodeRuneInString(sample)
+		if r == utf8.RuneError && size == 1 && len(sample) > utf8.UTFMax {
+			return true
+		}
+		sample = sample[size:]
+	}
+	return false
+}
+
+func renderJSONBody(body string, width int) (string, error) {
+	return FormatJSON(body), nil
+}
+
+var markupTagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+
+// renderXMLBody re-indents XML without resolving namespaces, so prefixes are
+// kept as written.
+func renderXMLBody(body string, width int) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	decoder.Strict = false
+
+	var b strings.Builder
+	var open []string
+	inline := false
+	newline := func() {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.Repeat("  ", len(open)))
+	}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			newline()
+			b.WriteString(markupTagStyle.Render("<" + xmlName(t.Name) + xmlAttrs(t.Attr) + ">"))
+			open = append(open, xmlName(t.Name))
+			inline = true
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != xmlName(t.Name) {
+				return "", fmt.Errorf("unexpected </%s>", xmlName(t.Name))
+			}
+			open = open[:len(open)-1]
+			if !inline {
+				newline()
+			}
+			b.WriteString(markupTagStyle.Render("</" + xmlName(t.Name) + ">"))
+			inline = false
+		case xml.CharData:
+			if text := strings.TrimSpace(string(t)); text != "" {
+				b.WriteString(xmlTextEscaper.Replace(text))
+			}
+		case xml.Comment:
+			inline = false
+			newline()
+			b.WriteString(quoteStyle.Render("<!--" + string(t) + "-->"))
+		case xml.ProcInst:
+			inline = false
+			newline()
+			b.WriteString(quoteStyle.Render("<?" + t.Target + " " + string(t.Inst) + "?>"))
+		case xml.Directive:
+			inline = false
+			newline()
+			b.WriteString(quoteStyle.Render("<!" + string(t) + ">"))
+		}
+	}
+	if b.Len() == 0 || len(open) > 0 {
+		return "", fmt.Errorf("incomplete XML document")
+	}
+	return b.String(), nil
+}
+
+var (
+	xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+)
+
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+func xmlAttrs(attrs []xml.Attr) string {
+	var b strings.Builder
+	for _, attr := range attrs {
+		b.WriteString(" " + xmlName(attr.Name) + `="` + xmlAttrEscaper.Replace(attr.Value) + `"`)
+	}
+	return b.String()
+}
+
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+var htmlBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "section": true, "article": true, "header": true,
+	"footer": true, "pre": true, "blockquote": true, "table": true, "ul": true, "ol": true, "title": true,
+}
+
+// renderHTMLBody shows a text-only preview of the page followed by its
+// indented source.
+func renderHTMLBody(body string, width int) (string, error) {
+	var source, preview strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	depth := 0
+	skip := ""
+	newline := func() {
+		if source.Len() > 0 {
+			source.WriteString("\n")
+		}
+		source.WriteString(strings.Repeat("  ", depth))
+	}
+
+	for {
+		kind := tokenizer.Next()
+		if kind == html.ErrorToken {
+			if err := tokenizer.Err(); err != io.EOF {
+				return "", err
+			}
+			break
+		}
+		raw := string(tokenizer.Raw())
+		token := tokenizer.Token()
+
+		switch kind {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			newline()
+			source.WriteString(markupTagStyle.Render(raw))
+			if kind == html.StartTagToken && !htmlVoidElements[token.Data] {
+				depth++
+			}
+			if token.Data == "script" || token.Data == "style" {
+				skip = token.Data
+			}
+			if htmlBlockElements[token.Data] {
+				preview.WriteString("\n")
+			}
+		case html.EndTagToken:
+			depth = max(depth-1, 0)
+			newline()
+			source.WriteString(markupTagStyle.Render(raw))
+			if token.Data == skip {
+				skip = ""
+			}
+			if htmlBlockElements[token.Data] {
+				preview.WriteString("\n")
+			}
+		case html.TextToken:
+			text := strings.TrimSpace(raw)
+			if text == "" {
+				continue
+			}
+			newline()
+			source.WriteString(text)
+			if skip == "" {
+				preview.WriteString(strings.Join(strings.Fields(token.Data), " ") + " ")
+			}
+		case html.CommentToken:
+			newline()
+			source.WriteString(quoteStyle.Render(raw))
+		case html.DoctypeToken:
+			newline()
+			source.WriteString(quoteStyle.Render(raw))
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(preview.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	wrap := lipgloss.NewStyle().Width(max(width-8, 20))
+	return headingStyle.Render("Preview") + "\n" + wrap.Render(strings.Join(lines, "\n")) +
+		"\n\n" + headingStyle.Render("Source") + "\n" + source.String(), nil
+}
+
+// renderYAMLBody normalises indentation and colours mapping keys. Comments
+// and key order are preserved.
+func renderYAMLBody(body string, width int) (string, error) {
+	var documents []string
+	decoder := yaml.NewDecoder(strings.NewReader(body))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return "", err
+		}
+		encoder.Close()
+		documents = append(documents, strings.TrimRight(out.String(), "\n"))
+	}
+
+	lines := strings.Split(strings.Join(documents, "\n---\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " -")
+		key, rest, found := strings.Cut(trimmed, ":")
+		if !found || strings.HasPrefix(trimmed, "#") || strings.ContainsAny(key, `"'{[`) ||
+			rest != "" && !strings.HasPrefix(rest, " ") {
+			continue
+		}
+		prefix := line[:len(line)-len(trimmed)]
+		lines[i] = prefix + jsonKeyStyle.Render(key) + ":" + jsonStringStyle.Render(rest)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func renderCSVBody(separator rune) func(string, int) (string, error) {
+	return func(body string, width int) (string, error) {
+		reader := csv.NewReader(strings.NewReader(body))
+		reader.Comma = separator
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		var rows [][]string
+		total := 0
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			total++
+			if len(rows) <= csvRowLimit {
+				rows = append(rows, record)
+			}
+		}
+		if len(rows) == 0 {
+			return "", fmt.Errorf("empty CSV document")
+		}
+
+		columns := 0
+		for _, row := range rows {
+			columns = max(columns, len(row))
+		}
+		for i := range rows {
+			for len(rows[i]) < columns {
+				rows[i] = append(rows[i], "")
+			}
+		}
+
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("240"))).
+			Headers(rows[0]...).
+			Rows(rows[1:]...).
+			Width(max(width-8, 20)).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Padding(0, 1)
+				}
+				return lipgloss.NewStyle().Padding(0, 1)
+			})
+
+		result := t.Render()
+		if total > len(rows) {
+			result += fmt.Sprintf("\n… %d more rows", total-len(rows))
+		}
+		return result, nil
+	}
+}
+
+// renderBinaryBody describes a binary body and shows the start of it as a
+// hex dump.
+func renderBinaryBody(body string, width int) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Binary body: %d bytes\n", len(body))
+	if config, format, err := image.DecodeConfig(strings.NewReader(body)); err == nil {
+		fmt.Fprintf(&b, "Image: %s, %dx%d\n", format, config.Width, config.Height)
+	}
+	b.WriteString("\n")
+	b.WriteString(hex.Dump([]byte(body[:min(len(body), hexDumpLimit)])))
+	if len(body) > hexDumpLimit {
+		fmt.Fprintf(&b, "… %d more bytes", len(body)-hexDumpLimit)
+	}
+	return b.String(), nil
+}
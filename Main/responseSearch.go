@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var (
+	searchMatchStyle   = lipgloss.NewStyle().Background(lipgloss.Color("58")).Foreground(lipgloss.Color("230"))
+	searchCurrentStyle = lipgloss.NewStyle().Background(lipgloss.Color("205")).Foreground(lipgloss.Color("0")).Bold(true)
+)
+
+// searchMatch is one regex match in the rendered ApiPage content.
+type searchMatch struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// compileSearch turns the search input into a regex. Patterns without
+// upper case letters match case-insensitively.
+func compileSearch(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if strings.ToLower(pattern) == pattern {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// highlightSearch finds every match in the rendered content and highlights
+// it. Lines with a match lose their other colours, since styles cannot be
+// nested inside already rendered text.
+func highlightSearch(content string, re *regexp.Regexp, current int) (string, []searchMatch) {
+	if re == nil {
+		return content, nil
+	}
+	lines := strings.Split(content, "\n")
+	var matches []searchMatch
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		found := re.FindAllStringIndex(plain, -1)
+		if len(found) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, loc := range found {
+			if loc[0] == loc[1] {
+				continue
+			}
+			style := searchMatchStyle
+			if len(matches) == current {
+				style = searchCurrentStyle
+			}
+			b.WriteString(plain[last:loc[0]])
+			b.WriteString(style.Render(plain[loc[0]:loc[1]]))
+			last = loc[1]
+			matches = append(matches, searchMatch{Line: i, Start: loc[0], End: loc[1]})
+		}
+		b.WriteString(plain[last:])
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n"), matches
+}
+
+// refreshApiContent rebuilds the ApiPage viewport, applying the current
+// search.
+func (m *model) refreshApiContent() {
+	if !m.viewportReady {
+		return
+	}
+	content := BuildApiPageContent(*m, m.termWidth)
+	re, err := compileSearch(m.searchInput.Value())
+	if err != nil {
+		m.searchError = err.Error()
+		re = nil
+	} else {
+		m.searchError = ""
+	}
+	content, m.searchMatches = highlightSearch(content, re, m.searchIndex)
+	if m.searchIndex >= len(m.searchMatches) {
+		m.searchIndex = 0
+	}
+	m.apiViewport.SetContent(content)
+}
+
+// jumpToMatch moves the current match by delta, wrapping around, and scrolls
+// it into view.
+func (m *model) jumpToMatch(delta int) {
+	if len(m.searchMatches) == 0 {
+		return
+	}
+	m.searchIndex = (m.searchIndex + delta + len(m.searchMatches)) % len(m.searchMatches)
+	m.refreshApiContent()
+	line := m.searchMatches[m.searchIndex].Line
+	if line < m.apiViewport.YOffset || line >= m.apiViewport.YOffset+m.apiViewport.Height {
+		m.apiViewport.SetYOffset(max(line-m.apiViewport.Height/3, 0))
+	}
+}
+
+func (m model) searchStatus() string {
+	if m.searchError != "" {
+		return m.searchError
+	}
+	if len(m.searchMatches) == 0 {
+		return "no matches"
+	}
+	return fmt.Sprintf("%d/%d", m.searchIndex+1, len(m.searchMatches))
+}
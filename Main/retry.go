@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultRetryOn    = "network,429,502,503,504"
+	defaultRetryBase  = 500 * time.Millisecond
+	defaultRetryLimit = 30 * time.Second
+)
+
+// RetryPolicy resends a request that failed in a retryable way. Zero
+// values mean the defaults; a request policy with MaxAttempts set replaces
+// the collection's policy. Only idempotent requests are sent again once the
+// server may have seen them; any other request is retried only when the
+// connection failed before it was written.
+type RetryPolicy struct {
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// RetryOn lists status codes, ranges like 500-599 and "network" for
+	// errors that produced no response, comma separated.
+	RetryOn     string `json:"retryOn,omitempty"`
+	BaseDelayMs int    `json:"baseDelayMs,omitempty"`
+	MaxDelayMs  int    `json:"maxDelayMs,omitempty"`
+}
+
+// RetryAttempt is one try at sending a request, for the response page.
+type RetryAttempt struct {
+	Attempt    int
+	StatusCode int
+	Status     string
+	Error      string
+	Duration   time.Duration
+	// Delay is the wait before the next attempt.
+	Delay time.Duration
+}
+
+func effectiveRetry(request RetryPolicy, collection RetryPolicy) RetryPolicy {
+	if request.MaxAttempts > 0 {
+		return request
+	}
+	return collection
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+func (p RetryPolicy) retryOn() string {
+	if p.RetryOn == "" {
+		return defaultRetryOn
+	}
+	return p.RetryOn
+}
+
+func (p RetryPolicy) baseDelay() time.Duration {
+	if p.BaseDelayMs > 0 {
+		return time.Duration(p.BaseDelayMs) * time.Millisecond
+	}
+	return defaultRetryBase
+}
+
+func (p RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelayMs > 0 {
+		return time.Duration(p.MaxDelayMs) * time.Millisecond
+	}
+	return defaultRetryLimit
+}
+
+// retryCondition is the parsed form of RetryOn.
+type retryCondition struct {
+	network bool
+	ranges  [][2]int
+}
+
+func parseRetryOn(value string) (retryCondition, error) {
+	var condition retryCondition
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if part == "network" {
+			condition.network = true
+			continue
+		}
+		low, high, isRange := strings.Cut(part, "-")
+		if !isRange {
+			high = low
+		}
+		from, err1 := strconv.Atoi(strings.TrimSpace(low))
+		to, err2 := strconv.Atoi(strings.TrimSpace(high))
+		if err1 != nil || err2 != nil || from < 100 || to > 599 || from > to {
+			return retryCondition{}, fmt.Errorf("%q is not a status code, range or \"network\"", part)
+		}
+		condition.ranges = append(condition.ranges, [2]int{from, to})
+	}
+	return condition, nil
+}
+
+func (c retryCondition) matches(resp *http.Response, err error) bool {
+	if err != nil {
+		return c.network
+	}
+	for _, r := range c.ranges {
+		if resp.StatusCode >= r[0] && resp.StatusCode <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// delay is the wait after the given attempt: exponential backoff with
+// jitter, or the server's Retry-After when it sends one. Both are capped
+// at the maximum delay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, p.maxDelay())
+		}
+	}
+	backoff := p.baseDelay() << min(attempt-1, 20)
+	backoff = min(backoff, p.maxDelay())
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date.
+func retryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(time.Until(when), 0), true
+	}
+	return 0, false
+}
+
+// idempotentMethods are safe to send twice, as in net/http.
+var idempotentMethods = []string{"GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE"}
+
+// idempotent reports whether req may be sent again after the server got it,
+// by its method or an idempotency key the server deduplicates on.
+func idempotent(req *http.Request) bool {
+	if slices.Contains(idempotentMethods, req.Method) {
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// doWithRetries sends req, and sends it again while the policy allows. The
+// response of the last attempt is returned with every attempt made.
+func doWithRetries(client *http.Client, req *http.Request, policy RetryPolicy, redirects *redirectRecorder) (*http.Response, []RetryAttempt, error) {
+	condition, err := parseRetryOn(policy.retryOn())
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := req.Context()
+
+	var attempts []RetryAttempt
+	for attempt := 1; ; attempt++ {
+		// The transport reports on its own goroutine once the request
+		// headers are on the wire; before that nothing reached the server.
+		var sent atomic.Bool
+		traced := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			WroteHeaders: func() { sent.Store(true) },
+		})
+		try := req.WithContext(traced)
+		if attempt > 1 {
+			try = req.Clone(traced)
+			if req.GetBody != nil {
+				if try.Body, err = req.GetBody(); err != nil {
+					return nil, attempts, err
+				}
+			}
+		}
+		redirects.reset()
+
+		start := time.Now()
+		resp, err := client.Do(try)
+		record := RetryAttempt{Attempt: attempt, Duration: time.Since(start)}
+		if err != nil {
+			record.Error = err.Error()
+		} else {
+			record.StatusCode = resp.StatusCode
+			record.Status = resp.Status
+		}
+
+		retryable := condition.matches(resp, err) && (idempotent(req) || !sent.Load())
+		if attempt >= policy.maxAttempts() || ctx.Err() != nil || !retryable {
+			attempts = append(attempts, record)
+			return resp, attempts, err
+		}
+
+		record.Delay = policy.delay(attempt, resp)
+		attempts = append(attempts, record)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(record.Delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempts, ctx.Err()
+		}
+	}
+}
+
+// describeAttempts lists the attempts for the response page when there was
+// more than one.
+func describeAttempts(attempts []RetryAttempt) string {
+	if len(attempts) < 2 {
+		return ""
+	}
+	var b strings.Builder
+	for i, attempt := range attempts {
+		outcome := attempt.Status
+		if attempt.Error != "" {
+			outcome = "error: " + attempt.Error
+		}
+		fmt.Fprintf(&b, "  %d. %s in %s", attempt.Attempt, outcome, attempt.Duration.Round(time.Millisecond))
+		if i < len(attempts)-1 {
+			fmt.Fprintf(&b, ", retried after %s", attempt.Delay.Round(time.Millisecond))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
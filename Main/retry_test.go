@@ -0,0 +1,168 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryOn(t *testing.T) {
+	condition, err := parseRetryOn(" Network, 429 ,500-504")
+	must(t, err)
+	if !condition.network || len(condition.ranges) != 2 {
+		t.Fatalf("condition = %+v", condition)
+	}
+	for status, want := range map[int]bool{429: true, 500: true, 503: true, 504: true, 505: false, 200: false} {
+		if got := condition.matches(&http.Response{StatusCode: status}, nil); got != want {
+			t.Errorf("matches(%d) = %v, want %v", status, got, want)
+		}
+	}
+	if !condition.matches(nil, io.ErrUnexpectedEOF) {
+		t.Error("network errors not matched")
+	}
+
+	if condition, err := parseRetryOn("503"); err != nil || condition.matches(nil, io.ErrUnexpectedEOF) {
+		t.Errorf("503 alone = %+v, %v", condition, err)
+	}
+	for _, bad := range []string{"abc", "99", "600", "504-500", "5xx"} {
+		if _, err := parseRetryOn(bad); err == nil {
+			t.Errorf("parseRetryOn(%q) accepted", bad)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelayMs: 100, MaxDelayMs: 1000}
+	for attempt, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 10: time.Second} {
+		for range 20 {
+			if got := policy.delay(attempt, nil); got < limit/2 || got > limit {
+				t.Errorf("delay(%d) = %s, want between %s and %s", attempt, got, limit/2, limit)
+			}
+		}
+	}
+
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {value}}}
+	}
+	if got := policy.delay(1, retryAfter("0")); got != 0 {
+		t.Errorf("Retry-After 0 = %s", got)
+	}
+	if got := policy.delay(1, retryAfter("120")); got != time.Second {
+		t.Errorf("Retry-After 120 = %s, want the 1s cap", got)
+	}
+	if got := policy.delay(1, retryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))); got != 0 {
+		t.Errorf("Retry-After in the past = %s", got)
+	}
+	if got := policy.delay(1, retryAfter("soon")); got < 50*time.Millisecond || got > 100*time.Millisecond {
+		t.Errorf("invalid Retry-After = %s, want the backoff", got)
+	}
+}
+
+// flakyServer answers 503 until the given attempt, then 200, and keeps the
+// body of every request it got.
+func flakyServer(t *testing.T, succeedOn int32) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) < succeedOn {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func retry(t *testing.T, req *http.Request, policy RetryPolicy) (*http.Response, []RetryAttempt, error) {
+	t.Helper()
+	redirects := newRedirectRecorder(RequestSettings{})
+	resp, attempts, err := doWithRetries(&http.Client{CheckRedirect: redirects.checkRedirect}, req, policy, redirects)
+	if resp != nil {
+		t.Cleanup(func() { resp.Body.Close() })
+	}
+	return resp, attempts, err
+}
+
+func TestDoWithRetriesResendsIdempotentRequests(t *testing.T) {
+	server, bodies := flakyServer(t, 3)
+	req, _ := http.NewRequest("PUT", server.URL, strings.NewReader(`{"name":"rex"}`))
+
+	resp, attempts, err := retry(t, req, RetryPolicy{MaxAttempts: 5, BaseDelayMs: 1})
+	must(t, err)
+	if resp.StatusCode != 200 || len(attempts) != 3 {
+		t.Fatalf("status %d after %d attempts", resp.StatusCode, len(attempts))
+	}
+	for i, body := range *bodies {
+		if body != `{"name":"rex"}` {
+			t.Errorf("attempt %d sent body %q", i+1, body)
+		}
+	}
+	if attempts[0].StatusCode != 503 || attempts[0].Delay != 0 || attempts[2].StatusCode != 200 {
+		t.Errorf("attempts = %+v", attempts)
+	}
+}
+
+func TestDoWithRetriesStopsAtMaxAttempts(t *testing.T) {
+	server, _ := flakyServer(t, 10)
+	req, _ := http.NewRequest("GET", server.URL, nil)
+
+	resp, attempts, err := retry(t, req, RetryPolicy{MaxAttempts: 2, BaseDelayMs: 1})
+	must(t, err)
+	if resp.StatusCode != 503 || len(attempts) != 2 {
+		t.Errorf("status %d after %d attempts", resp.StatusCode, len(attempts))
+	}
+}
+
+func TestDoWithRetriesKeepsPostsTheServerSaw(t *testing.T) {
+	server, _ := flakyServer(t, 3)
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(`{}`))
+	resp, attempts, err := retry(t, req, RetryPolicy{MaxAttempts: 5, BaseDelayMs: 1})
+	must(t, err)
+	if resp.StatusCode != 503 || len(attempts) != 1 {
+		t.Errorf("POST: status %d after %d attempts", resp.StatusCode, len(attempts))
+	}
+
+	// A dropped connection after the request went out may still have
+	// created something on the server.
+	dropped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer dropped.Close()
+	req, _ = http.NewRequest("POST", dropped.URL, strings.NewReader(`{}`))
+	if _, attempts, err := retry(t, req, RetryPolicy{MaxAttempts: 5, BaseDelayMs: 1}); err == nil || len(attempts) != 1 {
+		t.Errorf("dropped POST: %d attempts, %v", len(attempts), err)
+	}
+
+	server, _ = flakyServer(t, 3)
+	req, _ = http.NewRequest("POST", server.URL, strings.NewReader(`{}`))
+	req.Header.Set("Idempotency-Key", "order-1")
+	resp, attempts, err = retry(t, req, RetryPolicy{MaxAttempts: 5, BaseDelayMs: 1})
+	must(t, err)
+	if resp.StatusCode != 200 || len(attempts) != 3 {
+		t.Errorf("POST with an idempotency key: status %d after %d attempts", resp.StatusCode, len(attempts))
+	}
+}
+
+func TestDoWithRetriesResendsUnsentPosts(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	req, _ := http.NewRequest("POST", url, strings.NewReader(`{}`))
+	_, attempts, err := retry(t, req, RetryPolicy{MaxAttempts: 3, BaseDelayMs: 1})
+	if err == nil || len(attempts) != 3 {
+		t.Errorf("refused POST: %d attempts, %v", len(attempts), err)
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const defaultMaxRedirects = 10
+
+// RequestSettings controls how a single request is sent. Zero values mean
+// the defaults, so requests without settings are stored without them.
+type RequestSettings struct {
+	FollowRedirects *bool  `json:"followRedirects,omitempty"`
+	MaxRedirects    int    `json:"maxRedirects,omitempty"`
+	HTTPVersion     string `json:"httpVersion,omitempty"`
+
+	Retry RetryPolicy `json:"retry,omitzero"`
+}
+
+// HTTP versions a request can be sent with. The default negotiates HTTP/2
+// over TLS and uses HTTP/1.1 otherwise.
+const (
+	httpVersionAuto = ""
+	httpVersion1    = "HTTP/1.1"
+	httpVersionH2C  = "h2c"
+)
+
+var httpVersions = []string{httpVersionAuto, httpVersion1, httpVersionH2C}
+
+func httpVersionName(version string) string {
+	if version == httpVersionAuto {
+		return "HTTP/2 over TLS, else HTTP/1.1"
+	}
+	if version == httpVersionH2C {
+		return "h2c (HTTP/2 without TLS)"
+	}
+	return version
+}
+
+// protocols returns which protocols the transport may use for the version.
+func (s RequestSettings) protocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	switch s.HTTPVersion {
+	case httpVersion1:
+		protocols.SetHTTP1(true)
+	case httpVersionH2C:
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	}
+	return protocols
+}
+
+// CollectionSettings apply to every request in a collection.
+type CollectionSettings struct {
+	TLS   TLSSettings   `json:"tls,omitzero"`
+	Proxy ProxySettings `json:"proxy,omitzero"`
+	Retry RetryPolicy   `json:"retry,omitzero"`
+}
+
+// settingsScope is what the settings page is editing.
+type settingsScope int
+
+const (
+	requestScope settingsScope = iota
+	collectionScope
+	globalScope
+)
+
+func (s RequestSettings) followRedirects() bool {
+	return s.FollowRedirects == nil || *s.FollowRedirects
+}
+
+func (s RequestSettings) maxRedirects() int {
+	if s.MaxRedirects > 0 {
+		return s.MaxRedirects
+	}
+	return defaultMaxRedirects
+}
+
+// settingRow is one line of the settings page. Rows with toggle flip or
+// cycle on enter; the others are edited as text. Both change the form's draft.
+type settingRow struct {
+	Label  string
+	Value  string
+	toggle func()
+	set    func(value string) error
+}
+
+// settingsForm is what the settings page edits: the rows over a draft of
+// the settings and how to store the draft once a row changed it.
+type settingsForm struct {
+	Title string
+	Rows  []settingRow
+	save  func(store Store) error
+}
+
+func (m model) settingsForm() settingsForm {
+	switch m.settingsScope {
+	case collectionScope:
+		return collectionSettingsForm(m.SelectedCollection)
+	case globalScope:
+		return globalSettingsForm(m.storage.Settings)
+	}
+	return requestSettingsForm(m.SelectedApi)
+}
+
+// savedSettingsForm follows a save of the settings page.
+func (m *model) savedSettingsForm() {
+	switch m.settingsScope {
+	case collectionScope:
+		m.savedCollection()
+	case globalScope:
+		m.savedSettings()
+	default:
+		m.savedRequest(m.SelectedApi.ID)
+	}
+}
+
+func requestSettingsForm(api Api) settingsForm {
+	settings := api.Settings
+	rows := []settingRow{
+		{
+			Label: "Follow redirects",
+			Value: yesNo(settings.followRedirects()),
+			toggle: func() {
+				follow := !settings.followRedirects()
+				settings.FollowRedirects = &follow
+				if follow {
+					settings.FollowRedirects = nil
+				}
+			},
+		},
+		intSetting("Max redirects", settings.maxRedirects(), 1, &settings.MaxRedirects),
+		{
+			Label: "HTTP version",
+			Value: httpVersionName(settings.HTTPVersion),
+			toggle: func() {
+				next := (slices.Index(httpVersions, settings.HTTPVersion) + 1) % len(httpVersions)
+				settings.HTTPVersion = httpVersions[next]
+			},
+		},
+	}
+	rows = append(rows, retryRows(&settings.Retry, "collection")...)
+	return settingsForm{
+		Title: "Settings: " + apiTitle(api),
+		Rows:  rows,
+		save: func(store Store) error {
+			api.Settings = settings
+			return store.UpdateRequest(api)
+		},
+	}
+}
+
+func collectionSettingsForm(collection Collection) settingsForm {
+	settings := collection.Settings
+	tls := &settings.TLS
+	rows := []settingRow{
+		textSetting("CA bundle", &tls.CAFile),
+		textSetting("Client certificate", &tls.CertFile),
+		textSetting("Client key", &tls.KeyFile),
+		{
+			Label:  "Skip certificate verification",
+			Value:  yesNo(tls.InsecureSkipVerify),
+			toggle: func() { tls.InsecureSkipVerify = !tls.InsecureSkipVerify },
+		},
+		{
+			Label: "Minimum TLS version",
+			Value: tls.minVersionName(),
+			set: func(value string) error {
+				value = strings.TrimSpace(value)
+				if value == "default" {
+					value = ""
+				}
+				if _, err := parseTLSVersion(value); err != nil {
+					return err
+				}
+				tls.MinVersion = value
+				return nil
+			},
+		},
+		textSetting("Server name (SNI)", &tls.ServerName),
+	}
+	rows = append(rows, proxyRows(&settings.Proxy, "global")...)
+	rows = append(rows, retryRows(&settings.Retry, "1")...)
+	return settingsForm{
+		Title: "Collection Settings: " + collection.Name,
+		Rows:  rows,
+		save: func(store Store) error {
+			return store.SetCollectionSettings(collection.ID, settings)
+		},
+	}
+}
+
+func globalSettingsForm(settings GlobalSettings) settingsForm {
+	return settingsForm{
+		Title: "Global Settings",
+		Rows:  proxyRows(&settings.Proxy, "environment"),
+		save: func(store Store) error {
+			return store.SetGlobalSettings(settings)
+		},
+	}
+}
+
+// proxyRows edit a proxy. An empty URL is shown as fallback, the proxy used
+// instead.
+func proxyRows(proxy *ProxySettings, fallback string) []settingRow {
+	address := textSetting("Proxy", &proxy.URL)
+	if proxy.URL == "" {
+		address.Value = fallback
+	}
+	address.set = func(input string) error {
+		input = strings.TrimSpace(input)
+		if input == fallback {
+			input = ""
+		}
+		if input != "" && input != proxyNone {
+			if _, err := proxyURL(ProxySettings{URL: input}); err != nil {
+				return err
+			}
+		}
+		proxy.URL = input
+		return nil
+	}
+
+	password := textSetting("Proxy password", &proxy.Password)
+	if proxy.Password != "" {
+		password.Value = strings.Repeat("•", 8)
+		password.set = func(input string) error {
+			if input != password.Value {
+				proxy.Password = input
+			}
+			return nil
+		}
+	}
+	return []settingRow{
+		address,
+		textSetting("Proxy username", &proxy.Username),
+		password,
+		textSetting("No proxy for", &proxy.NoProxy),
+	}
+}
+
+// retryRows edit a retry policy. Until max attempts is set the policy is
+// not used and fallback is shown instead.
+func retryRows(policy *RetryPolicy, fallback string) []settingRow {
+	attempts := intSetting("Max attempts", policy.MaxAttempts, 1, &policy.MaxAttempts)
+	if policy.MaxAttempts == 0 {
+		attempts.Value = fallback
+	}
+	set := attempts.set
+	attempts.set = func(input string) error {
+		if strings.TrimSpace(input) == fallback {
+			input = ""
+		}
+		return set(input)
+	}
+
+	retryOn := textSetting("Retry on", &policy.RetryOn)
+	retryOn.Value = policy.retryOn()
+	retryOn.set = func(input string) error {
+		input = strings.TrimSpace(input)
+		if _, err := parseRetryOn(input); err != nil {
+			return err
+		}
+		policy.RetryOn = input
+		if input == defaultRetryOn {
+			policy.RetryOn = ""
+		}
+		return nil
+	}
+
+	return []settingRow{
+		attempts,
+		retryOn,
+		intSetting("Backoff base (ms)", int(policy.baseDelay().Milliseconds()), 1, &policy.BaseDelayMs),
+		intSetting("Backoff max (ms)", int(policy.maxDelay().Milliseconds()), 1, &policy.MaxDelayMs),
+	}
+}
+
+func textSetting(label string, value *string) settingRow {
+	return settingRow{
+		Label: label,
+		Value: *value,
+		set: func(input string) error {
+			*value = strings.TrimSpace(input)
+			return nil
+		},
+	}
+}
+
+// intSetting edits a number where zero stands for the default, which is
+// shown instead.
+func intSetting(label string, shown int, minimum int, value *int) settingRow {
+	return settingRow{
+		Label: label,
+		Value: strconv.Itoa(shown),
+		set: func(input string) error {
+			n, err := parseSettingInt(input, minimum)
+			if err != nil {
+				return err
+			}
+			*value = n
+			return nil
+		},
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// parseSettingInt reads a numeric setting. An empty value resets it to the
+// default.
+func parseSettingInt(value string, minimum int) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < minimum {
+		return 0, fmt.Errorf("%q is not a number of at least %d", value, minimum)
+	}
+	return n, nil
+}
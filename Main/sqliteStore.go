@@ -0,0 +1,1574 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// dbPath switches storage to the SQLite backend.
+var dbPath string
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS collections (
+	id       INTEGER PRIMARY KEY,
+	position INTEGER NOT NULL,
+	name     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS requests (
+	id            INTEGER PRIMARY KEY,
+	collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	method        TEXT NOT NULL,
+	url           TEXT NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS requests_by_collection ON requests(collection_id, position);
+CREATE TABLE IF NOT EXISTS environment_variables (
+	id            INTEGER PRIMARY KEY,
+	collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	key           TEXT NOT NULL,
+	value         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS variables_by_collection ON environment_variables(collection_id, position);
+CREATE TABLE IF NOT EXISTS history (
+	id               INTEGER PRIMARY KEY,
+	request_id       INTEGER REFERENCES requests(id) ON DELETE SET NULL,
+	sent_at          INTEGER NOT NULL,
+	method           TEXT NOT NULL,
+	url              TEXT NOT NULL,
+	status_code      INTEGER NOT NULL,
+	status           TEXT NOT NULL,
+	response_headers TEXT NOT NULL,
+	response_body    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_by_request ON history(request_id, sent_at);
+CREATE INDEX IF NOT EXISTS history_by_time ON history(sent_at);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS requests_search USING fts5(method, url, content='requests', content_rowid='id');
+CREATE TRIGGER IF NOT EXISTS requests_search_insert AFTER INSERT ON requests BEGIN
+	INSERT INTO requests_search(rowid, method, url) VALUES (new.id, new.method, new.url);
+END;
+CREATE TRIGGER IF NOT EXISTS requests_search_delete AFTER DELETE ON requests BEGIN
+	INSERT INTO requests_search(requests_search, rowid, method, url) VALUES ('delete', old.id, old.method, old.url);
+END;
+CREATE TRIGGER IF NOT EXISTS requests_search_update AFTER UPDATE OF method, url ON requests BEGIN
+	INSERT INTO requests_search(requests_search, rowid, method, url) VALUES ('delete', old.id, old.method, old.url);
+	INSERT INTO requests_search(rowid, method, url) VALUES (new.id, new.method, new.url);
+END;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS history_search USING fts5(url, response_body, content='history', content_rowid='id');
+CREATE TRIGGER IF NOT EXISTS history_search_insert AFTER INSERT ON history BEGIN
+	INSERT INTO history_search(rowid, url, response_body) VALUES (new.id, new.url, new.response_body);
+END;
+CREATE TRIGGER IF NOT EXISTS history_search_delete AFTER DELETE ON history BEGIN
+	INSERT INTO history_search(history_search, rowid, url, response_body) VALUES ('delete', old.id, old.url, old.response_body);
+END;
+`
+
+// sqliteMigrations[i] upgrades the schema from user_version i+1 to i+2;
+// sqliteSchema itself is version 1.
+var sqliteMigrations = []func(tx *sql.Tx) error{
+	sqliteAddUIDs,
+	sqliteAddFolders,
+	sqliteAddDescriptions,
+	sqliteAddEnabled,
+	sqliteAddCookies,
+	sqliteAddCollectionSettings,
+	sqliteSearchNames,
+	sqliteDisabledFlag,
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version == 0 {
+		version = 1
+	}
+	if version > len(sqliteMigrations)+1 {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(sqliteMigrations)+1)
+	}
+	for ; version <= len(sqliteMigrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := sqliteMigrations[version-1](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to migrate database to version %d: %w", version+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sqliteAddUIDs(tx *sql.Tx) error {
+	for _, table := range []string{"collections", "environment_variables"} {
+		if _, err := tx.Exec(`ALTER TABLE ` + table + ` ADD COLUMN uid TEXT`); err != nil {
+			return err
+		}
+		rows, err := tx.Query(`SELECT id FROM ` + table)
+		if err != nil {
+			return err
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		for _, id := range ids {
+			if _, err := tx.Exec(`UPDATE `+table+` SET uid = ? WHERE id = ?`, newID(), id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`CREATE UNIQUE INDEX ` + table + `_by_uid ON ` + table + `(uid)`); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`ALTER TABLE requests ADD COLUMN uid TEXT`); err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT id, data FROM requests`)
+	if err != nil {
+		return err
+	}
+	updated := map[int64]Api{}
+	for rows.Next() {
+		var id int64
+		var data string
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		var api Api
+		if err := json.Unmarshal([]byte(data), &api); err != nil {
+			rows.Close()
+			return err
+		}
+		ensureApiIDs(&api)
+		updated[id] = api
+	}
+	rows.Close()
+	for id, api := range updated {
+		data, err := json.Marshal(api)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE requests SET uid = ?, data = ? WHERE id = ?`, api.ID, string(data), id); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`CREATE UNIQUE INDEX requests_by_uid ON requests(uid)`)
+	return err
+}
+
+func sqliteAddFolders(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE folders (
+	id            INTEGER PRIMARY KEY,
+	uid           TEXT NOT NULL UNIQUE,
+	collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+	parent_id     INTEGER REFERENCES folders(id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	name          TEXT NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX folders_by_parent ON folders(collection_id, parent_id, position);
+ALTER TABLE requests ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE CASCADE;
+CREATE INDEX requests_by_folder ON requests(folder_id, position);
+`)
+	return err
+}
+
+func sqliteAddDescriptions(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE collections ADD COLUMN description TEXT NOT NULL DEFAULT ''`)
+	return err
+}
+
+// sqliteAddEnabled marks every existing variable, header, query param and
+// body field as enabled, including those stored inside JSON data columns.
+func sqliteAddEnabled(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE environment_variables ADD COLUMN enabled INTEGER NOT NULL DEFAULT 1`); err != nil {
+		return err
+	}
+	return rewriteItems(tx, enableItem)
+}
+
+// sqliteDisabledFlag swaps the enabled flag in JSON data columns for the
+// disabled one the data file uses from version 5.
+func sqliteDisabledFlag(tx *sql.Tx) error {
+	return rewriteItems(tx, disableItem)
+}
+
+// rewriteItems applies fn to the headers, query params, body fields and
+// variables kept inside the requests and folders data columns.
+func rewriteItems(tx *sql.Tx, fn func(map[string]interface{})) error {
+	for table, walk := range map[string]func(map[string]interface{}, func(map[string]interface{})) error{
+		"requests": walkRequestItems,
+		"folders":  walkFolderItems,
+	} {
+		rows, err := tx.Query(`SELECT id, data FROM ` + table)
+		if err != nil {
+			return err
+		}
+		updated := map[int64]string{}
+		for rows.Next() {
+			var id int64
+			var data string
+			if err := rows.Scan(&id, &data); err != nil {
+				rows.Close()
+				return err
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal([]byte(data), &doc); err != nil {
+				rows.Close()
+				return err
+			}
+			if err := walk(doc, fn); err != nil {
+				rows.Close()
+				return err
+			}
+			migrated, err := json.Marshal(doc)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			updated[id] = string(migrated)
+		}
+		rows.Close()
+		for id, data := range updated {
+			if _, err := tx.Exec(`UPDATE `+table+` SET data = ? WHERE id = ?`, data, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sqliteAddCookies(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE collections ADD COLUMN cookies TEXT NOT NULL DEFAULT '[]'`)
+	return err
+}
+
+func sqliteAddCollectionSettings(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE collections ADD COLUMN settings TEXT NOT NULL DEFAULT '{}'`)
+	return err
+}
+
+// sqliteSearchNames rebuilds requests_search to cover the name and
+// description kept in requests.data. The index holds its own copy of the
+// text since those are not columns of requests.
+func sqliteSearchNames(tx *sql.Tx) error {
+	for _, statement := range []string{
+		`DROP TRIGGER requests_search_insert`,
+		`DROP TRIGGER requests_search_delete`,
+		`DROP TRIGGER requests_search_update`,
+		`DROP TABLE requests_search`,
+		`CREATE VIRTUAL TABLE requests_search USING fts5(method, url, name, description)`,
+		`CREATE TRIGGER requests_search_insert AFTER INSERT ON requests BEGIN
+			INSERT INTO requests_search(rowid, method, url, name, description)
+			VALUES (new.id, new.method, new.url, COALESCE(json_extract(new.data, '$.name'), ''), COALESCE(json_extract(new.data, '$.description'), ''));
+		END`,
+		`CREATE TRIGGER requests_search_delete AFTER DELETE ON requests BEGIN
+			DELETE FROM requests_search WHERE rowid = old.id;
+		END`,
+		`CREATE TRIGGER requests_search_update AFTER UPDATE OF method, url, data ON requests BEGIN
+			DELETE FROM requests_search WHERE rowid = old.id;
+			INSERT INTO requests_search(rowid, method, url, name, description)
+			VALUES (new.id, new.method, new.url, COALESCE(json_extract(new.data, '$.name'), ''), COALESCE(json_extract(new.data, '$.description'), ''));
+		END`,
+		`INSERT INTO requests_search(rowid, method, url, name, description)
+			SELECT id, method, url, COALESCE(json_extract(data, '$.name'), ''), COALESCE(json_extract(data, '$.description'), '') FROM requests`,
+	} {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderData is the JSON kept in folders.data.
+type folderData struct {
+	Description    string          `json:"description,omitempty"`
+	Headers        []Header        `json:"headers"`
+	Auth           *Auth           `json:"auth,omitempty"`
+	LocalVariables []LocalVariable `json:"localVariables"`
+}
+
+type HistoryEntry struct {
+	SentAt          time.Time
+	Method          string
+	Url             string
+	StatusCode      int
+	Status          string
+	ResponseHeaders http.Header
+	ResponseBody    string
+}
+
+const (
+	maxHistoryPerRequest = 50
+	maxHistoryEntries    = 2000
+	// maxHistoryBody is how much of each response body the history keeps;
+	// the preview only shows its first lines.
+	maxHistoryBody = 64 << 10
+)
+
+// HistoryStore is implemented by backends that keep a response history.
+type HistoryStore interface {
+	RecordHistory(apiID string, entry HistoryEntry) error
+	SearchHistory(query string, limit int) ([]HistoryEntry, error)
+	SearchRequests(query string, limit int) ([]Api, error)
+}
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create database schema: %w", err)
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store := &sqliteStore{db: db}
+	if err := store.importJSONOnce(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// importJSONOnce copies the JSON data file into an empty database the first
+// time it is opened.
+func (s *sqliteStore) importJSONOnce() error {
+	var imported string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'json_imported'`).Scan(&imported)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read database metadata: %w", err)
+	}
+
+	var storage Storage
+	if _, statErr := os.Stat(fileName); statErr == nil {
+		storage, err = ReadFile()
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", fileName, err)
+		}
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM collections`).Scan(&count); err != nil {
+			return err
+		}
+		if count == 0 {
+			if err := insertStorage(tx, storage); err != nil {
+				return fmt.Errorf("failed to import %s: %w", fileName, err)
+			}
+		}
+		_, err := tx.Exec(`INSERT INTO meta(key, value) VALUES ('json_imported', ?)`, fileName)
+		return err
+	})
+}
+
+// RestoreCollections writes collections back as they were. Their rows are
+// updated in place by ID, so requests keep their response history.
+func (s *sqliteStore) RestoreCollections(collections []Collection, order []string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM collections`)
+		if err != nil {
+			return err
+		}
+		for i, collection := range collections {
+			if err := insertCollection(tx, position+i, collection); err != nil {
+				return err
+			}
+		}
+		// Prune only once every collection is written, so a request that
+		// moves back to another collection is updated rather than deleted.
+		for _, collection := range collections {
+			if err := pruneCollection(tx, collection); err != nil {
+				return err
+			}
+		}
+		if order == nil {
+			return nil
+		}
+		return sortCollections(tx, order)
+	})
+}
+
+// pruneCollection deletes the requests, folders and variables stored under
+// a collection that it no longer holds.
+func pruneCollection(tx *sql.Tx, collection Collection) error {
+	keep := map[string]bool{}
+	for _, variable := range collection.LocalVariables {
+		keep[variable.ID] = true
+	}
+	for _, api := range allApis(collection) {
+		keep[api.ID] = true
+	}
+	var walk func(folders []Folder)
+	walk = func(folders []Folder) {
+		for _, folder := range folders {
+			keep[folder.ID] = true
+			walk(folder.Folders)
+		}
+	}
+	walk(collection.Folders)
+
+	for _, table := range []string{"requests", "folders", "environment_variables"} {
+		rows, err := tx.Query(`SELECT id, uid FROM `+table+` WHERE collection_id = (SELECT id FROM collections WHERE uid = ?)`, collection.ID)
+		if err != nil {
+			return err
+		}
+		var stale []int64
+		for rows.Next() {
+			var id int64
+			var uid string
+			if err := rows.Scan(&id, &uid); err != nil {
+				rows.Close()
+				return err
+			}
+			if !keep[uid] {
+				stale = append(stale, id)
+			}
+		}
+		rows.Close()
+		for _, id := range stale {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE id = ?`, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortCollections puts the collections in order and deletes the ones it
+// does not list.
+func sortCollections(tx *sql.Tx, order []string) error {
+	rows, err := tx.Query(`SELECT id, uid FROM collections`)
+	if err != nil {
+		return err
+	}
+	positions := map[int64]int{}
+	for rows.Next() {
+		var id int64
+		var uid string
+		if err := rows.Scan(&id, &uid); err != nil {
+			rows.Close()
+			return err
+		}
+		positions[id] = slices.Index(order, uid)
+	}
+	rows.Close()
+	for id, position := range positions {
+		if position < 0 {
+			_, err = tx.Exec(`DELETE FROM collections WHERE id = ?`, id)
+		} else {
+			_, err = tx.Exec(`UPDATE collections SET position = ? WHERE id = ?`, position, id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertStorage(tx *sql.Tx, storage Storage) error {
+	ensureIDs(&storage)
+	if err := writeGlobalSettings(tx, storage.Settings); err != nil {
+		return err
+	}
+	for i, collection := range storage.Collections {
+		if err := insertCollection(tx, i, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertCollection adds a collection with everything in it. Rows that
+// already exist with the same IDs are updated in place instead; the
+// collection then keeps its position and cookie jar.
+func insertCollection(tx *sql.Tx, position int, collection Collection) error {
+	cookies, err := cookiesJSON(collection.Cookies)
+	if err != nil {
+		return err
+	}
+	settings, err := json.Marshal(collection.Settings)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO collections(uid, position, name, description, cookies, settings) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(uid) DO UPDATE SET name = excluded.name, description = excluded.description, settings = excluded.settings`,
+		collection.ID, position, collection.Name, collection.Description, cookies, string(settings)); err != nil {
+		return err
+	}
+	collectionID, err := rowID(tx, "collections", collection.ID, "collection not found")
+	if err != nil {
+		return err
+	}
+	for j, api := range collection.Requests {
+		if err := insertApi(tx, collectionID, nil, j, api); err != nil {
+			return err
+		}
+	}
+	if err := insertFolders(tx, collectionID, nil, collection.Folders); err != nil {
+		return err
+	}
+	for j, variable := range collection.LocalVariables {
+		if _, err := tx.Exec(`INSERT INTO environment_variables(uid, collection_id, position, key, value, enabled) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(uid) DO UPDATE SET collection_id = excluded.collection_id, position = excluded.position,
+				key = excluded.key, value = excluded.value, enabled = excluded.enabled`,
+			variable.ID, collectionID, j, variable.Key, variable.Value, variable.isEnabled()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertFolders(tx *sql.Tx, collectionID int64, parentID interface{}, folders []Folder) error {
+	for i, folder := range folders {
+		data, err := json.Marshal(folderData{Description: folder.Description, Headers: folder.Headers, Auth: folder.Auth, LocalVariables: folder.LocalVariables})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO folders(uid, collection_id, parent_id, position, name, data) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(uid) DO UPDATE SET collection_id = excluded.collection_id, parent_id = excluded.parent_id,
+				position = excluded.position, name = excluded.name, data = excluded.data`,
+			folder.ID, collectionID, parentID, i, folder.Name, string(data)); err != nil {
+			return err
+		}
+		folderID, err := rowID(tx, "folders", folder.ID, "folder not found")
+		if err != nil {
+			return err
+		}
+		for j, api := range folder.Requests {
+			if err := insertApi(tx, collectionID, folderID, j, api); err != nil {
+				return err
+			}
+		}
+		if err := insertFolders(tx, collectionID, folderID, folder.Folders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertApi(tx *sql.Tx, collectionID int64, folderID interface{}, position int, api Api) error {
+	ensureApiIDs(&api)
+	data, err := json.Marshal(api)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO requests(uid, collection_id, folder_id, position, method, url, data) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(uid) DO UPDATE SET collection_id = excluded.collection_id, folder_id = excluded.folder_id,
+			position = excluded.position, method = excluded.method, url = excluded.url, data = excluded.data`,
+		api.ID, collectionID, folderID, position, api.Method, api.Url, string(data))
+	return err
+}
+
+func (s *sqliteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Load() (Storage, error) {
+	storage := Storage{Version: storageVersion, Collections: []Collection{}}
+
+	var settings string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE key = 'settings'`).Scan(&settings)
+	if err != nil && err != sql.ErrNoRows {
+		return Storage{}, fmt.Errorf("failed to load settings: %w", err)
+	}
+	if settings != "" {
+		if err := json.Unmarshal([]byte(settings), &storage.Settings); err != nil {
+			return Storage{}, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	}
+
+	rows, err := s.db.Query(`SELECT id, uid, name, description, cookies, settings FROM collections ORDER BY position, id`)
+	if err != nil {
+		return Storage{}, fmt.Errorf("failed to load collections: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		id, collection, err := scanCollection(rows)
+		if err != nil {
+			rows.Close()
+			return Storage{}, err
+		}
+		ids = append(ids, id)
+		storage.Collections = append(storage.Collections, collection)
+	}
+	rows.Close()
+
+	for i, id := range ids {
+		if err := s.loadContents(id, &storage.Collections[i]); err != nil {
+			return Storage{}, err
+		}
+	}
+	return storage, nil
+}
+
+func (s *sqliteStore) LoadCollection(collectionID string) (Collection, error) {
+	row := s.db.QueryRow(`SELECT id, uid, name, description, cookies, settings FROM collections WHERE uid = ?`, collectionID)
+	id, collection, err := scanCollection(row)
+	if err != nil {
+		return Collection{}, err
+	}
+	if err := s.loadContents(id, &collection); err != nil {
+		return Collection{}, err
+	}
+	return collection, nil
+}
+
+func scanCollection(row interface {
+	Scan(dest ...interface{}) error
+}) (int64, Collection, error) {
+	var id int64
+	var cookies, settings string
+	var collection Collection
+	err := row.Scan(&id, &collection.ID, &collection.Name, &collection.Description, &cookies, &settings)
+	if err == sql.ErrNoRows {
+		return 0, Collection{}, fmt.Errorf("collection not found")
+	}
+	if err != nil {
+		return 0, Collection{}, fmt.Errorf("failed to load collections: %w", err)
+	}
+	if err := json.Unmarshal([]byte(cookies), &collection.Cookies); err != nil {
+		return 0, Collection{}, fmt.Errorf("failed to parse cookies of %s: %w", collection.Name, err)
+	}
+	if err := json.Unmarshal([]byte(settings), &collection.Settings); err != nil {
+		return 0, Collection{}, fmt.Errorf("failed to parse settings of %s: %w", collection.Name, err)
+	}
+	return id, collection, nil
+}
+
+// loadContents fills in a collection's requests, folders and variables.
+func (s *sqliteStore) loadContents(id int64, collection *Collection) error {
+	apis, folders, err := s.loadTree(id)
+	if err != nil {
+		return err
+	}
+	variables, err := s.loadVariables(id)
+	if err != nil {
+		return err
+	}
+	collection.Requests = apis
+	collection.Folders = folders
+	collection.LocalVariables = variables
+	return nil
+}
+
+// loadTree returns the collection's root requests and its folder hierarchy.
+func (s *sqliteStore) loadTree(collectionID int64) ([]Api, []Folder, error) {
+	type folderRow struct {
+		folder   Folder
+		parentID sql.NullInt64
+	}
+	rows, err := s.db.Query(`SELECT id, uid, parent_id, name, data FROM folders WHERE collection_id = ? ORDER BY position, id`, collectionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load folders: %w", err)
+	}
+	var order []int64
+	folders := map[int64]*folderRow{}
+	for rows.Next() {
+		var id int64
+		var data string
+		row := &folderRow{}
+		if err := rows.Scan(&id, &row.folder.ID, &row.parentID, &row.folder.Name, &data); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to load folders: %w", err)
+		}
+		var settings folderData
+		if err := json.Unmarshal([]byte(data), &settings); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to parse folder %s: %w", row.folder.Name, err)
+		}
+		row.folder.Description = settings.Description
+		row.folder.Headers = settings.Headers
+		row.folder.Auth = settings.Auth
+		row.folder.LocalVariables = settings.LocalVariables
+		folders[id] = row
+		order = append(order, id)
+	}
+	rows.Close()
+
+	requests := map[int64][]Api{}
+	apiRows, err := s.db.Query(`SELECT COALESCE(folder_id, 0), uid, method, url, data FROM requests WHERE collection_id = ? ORDER BY position, id`, collectionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load requests: %w", err)
+	}
+	defer apiRows.Close()
+	for apiRows.Next() {
+		var folderID int64
+		var uid, method, url, data string
+		if err := apiRows.Scan(&folderID, &uid, &method, &url, &data); err != nil {
+			return nil, nil, fmt.Errorf("failed to load requests: %w", err)
+		}
+		var api Api
+		if err := json.Unmarshal([]byte(data), &api); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse request %s %s: %w", method, url, err)
+		}
+		api.ID = uid
+		api.Method = method
+		api.Url = url
+		requests[folderID] = append(requests[folderID], api)
+	}
+	if err := apiRows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var build func(parent int64) []Folder
+	build = func(parent int64) []Folder {
+		var children []Folder
+		for _, id := range order {
+			row := folders[id]
+			if row.parentID.Int64 != parent {
+				continue
+			}
+			folder := row.folder
+			folder.Requests = requests[id]
+			folder.Folders = build(id)
+			children = append(children, folder)
+		}
+		return children
+	}
+	return requests[0], build(0), nil
+}
+
+func (s *sqliteStore) loadVariables(collectionID int64) ([]LocalVariable, error) {
+	rows, err := s.db.Query(`SELECT uid, key, value, enabled FROM environment_variables WHERE collection_id = ? ORDER BY position, id`, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variables: %w", err)
+	}
+	defer rows.Close()
+
+	var variables []LocalVariable
+	for rows.Next() {
+		var variable LocalVariable
+		var enabled bool
+		if err := rows.Scan(&variable.ID, &variable.Key, &variable.Value, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to load variables: %w", err)
+		}
+		variable.Disabled = !enabled
+		variables = append(variables, variable)
+	}
+	return variables, rows.Err()
+}
+
+func rowID(tx *sql.Tx, table string, uid string, notFound string) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`SELECT id FROM `+table+` WHERE uid = ?`, uid).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%s", notFound)
+	}
+	return id, err
+}
+
+func nextPosition(tx *sql.Tx, query string, args ...interface{}) (int, error) {
+	var position int
+	err := tx.QueryRow(query, args...).Scan(&position)
+	return position, err
+}
+
+func (s *sqliteStore) AddCollection(name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM collections`)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO collections(uid, position, name) VALUES (?, ?, ?)`, newID(), position, name)
+		return err
+	})
+}
+
+func (s *sqliteStore) RenameCollection(collectionID string, name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	return s.exec(`UPDATE collections SET name = ? WHERE uid = ?`, "collection not found", name, collectionID)
+}
+
+func (s *sqliteStore) SetCollectionDescription(collectionID string, description string) error {
+	return s.exec(`UPDATE collections SET description = ? WHERE uid = ?`, "collection not found", description, collectionID)
+}
+
+func (s *sqliteStore) SetCookies(collectionID string, cookies []Cookie) error {
+	data, err := cookiesJSON(cookies)
+	if err != nil {
+		return err
+	}
+	return s.exec(`UPDATE collections SET cookies = ? WHERE uid = ?`, "collection not found", data, collectionID)
+}
+
+func (s *sqliteStore) SetCollectionSettings(collectionID string, settings CollectionSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return s.exec(`UPDATE collections SET settings = ? WHERE uid = ?`, "collection not found", string(data), collectionID)
+}
+
+func (s *sqliteStore) SetGlobalSettings(settings GlobalSettings) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return writeGlobalSettings(tx, settings)
+	})
+}
+
+func writeGlobalSettings(tx *sql.Tx, settings GlobalSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO meta(key, value) VALUES ('settings', ?)`, string(data))
+	return err
+}
+
+func cookiesJSON(cookies []Cookie) (string, error) {
+	if cookies == nil {
+		cookies = []Cookie{}
+	}
+	data, err := json.Marshal(cookies)
+	return string(data), err
+}
+
+func (s *sqliteStore) DeleteCollection(collectionID string) error {
+	return s.exec(`DELETE FROM collections WHERE uid = ?`, "collection not found", collectionID)
+}
+
+func (s *sqliteStore) DuplicateCollection(collectionID string) error {
+	storage, err := s.Load()
+	if err != nil {
+		return err
+	}
+	i := indexByID(storage.Collections, collectionID)
+	if i < 0 {
+		return fmt.Errorf("collection not found")
+	}
+	folder := collectionFolder(storage.Collections[i], true)
+	duplicate := Storage{Collections: []Collection{{
+		Name:           copyName(storage.Collections[i].Name),
+		Description:    storage.Collections[i].Description,
+		Requests:       folder.Requests,
+		Folders:        folder.Folders,
+		LocalVariables: folder.LocalVariables,
+		Settings:       storage.Collections[i].Settings,
+	}}}
+	ensureIDs(&duplicate)
+
+	return s.inTx(func(tx *sql.Tx) error {
+		var position int
+		if err := tx.QueryRow(`SELECT position FROM collections WHERE uid = ?`, collectionID).Scan(&position); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE collections SET position = position + 1 WHERE position > ?`, position); err != nil {
+			return err
+		}
+		return insertCollection(tx, position+1, duplicate.Collections[0])
+	})
+}
+
+func (s *sqliteStore) ReorderCollection(collectionID string, delta int) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return shiftRow(tx, "collections", "1 = 1", collectionID, delta)
+	})
+}
+
+// MoveCollection re-parents the collection's rows under a new folder so that
+// request history stays attached.
+func (s *sqliteStore) MoveCollection(collectionID string, targetCollectionID string) error {
+	if collectionID == targetCollectionID {
+		return fmt.Errorf("cannot nest a collection inside itself")
+	}
+	storage, err := s.Load()
+	if err != nil {
+		return err
+	}
+	i := indexByID(storage.Collections, collectionID)
+	j := indexByID(storage.Collections, targetCollectionID)
+	if i < 0 || j < 0 {
+		return fmt.Errorf("collection not found")
+	}
+	if err := checkNestable(storage.Collections[i], storage.Collections[j]); err != nil {
+		return err
+	}
+	data, err := json.Marshal(folderData{Description: storage.Collections[i].Description, LocalVariables: storage.Collections[i].LocalVariables})
+	if err != nil {
+		return err
+	}
+	cookies, err := cookiesJSON(mergeCookies(storage.Collections[j].Cookies, storage.Collections[i].Cookies))
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		source, err := rowID(tx, "collections", collectionID, "collection not found")
+		if err != nil {
+			return err
+		}
+		target, err := rowID(tx, "collections", targetCollectionID, "collection not found")
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM folders WHERE collection_id = ? AND parent_id IS NULL`, target)
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec(`INSERT INTO folders(uid, collection_id, parent_id, position, name, data) VALUES (?, ?, NULL, ?, ?, ?)`,
+			collectionID, target, position, storage.Collections[i].Name, string(data))
+		if err != nil {
+			return err
+		}
+		folder, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, query := range []string{
+			`UPDATE folders SET parent_id = ? WHERE collection_id = ? AND parent_id IS NULL`,
+			`UPDATE requests SET folder_id = ? WHERE collection_id = ? AND folder_id IS NULL`,
+		} {
+			if _, err := tx.Exec(query, folder, source); err != nil {
+				return err
+			}
+		}
+		for _, table := range []string{"folders", "requests"} {
+			if _, err := tx.Exec(`UPDATE `+table+` SET collection_id = ? WHERE collection_id = ?`, target, source); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`UPDATE collections SET cookies = ? WHERE id = ?`, cookies, target); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM collections WHERE id = ?`, source)
+		return err
+	})
+}
+
+func (s *sqliteStore) CopyCollection(collectionID string, targetCollectionID string) error {
+	if collectionID == targetCollectionID {
+		return fmt.Errorf("cannot nest a collection inside itself")
+	}
+	storage, err := s.Load()
+	if err != nil {
+		return err
+	}
+	i := indexByID(storage.Collections, collectionID)
+	j := indexByID(storage.Collections, targetCollectionID)
+	if i < 0 || j < 0 {
+		return fmt.Errorf("collection not found")
+	}
+	if err := checkNestable(storage.Collections[i], storage.Collections[j]); err != nil {
+		return err
+	}
+	folder := collectionFolder(storage.Collections[i], true)
+	ensureFolderIDs(&folder)
+	cookies, err := cookiesJSON(mergeCookies(storage.Collections[j].Cookies, storage.Collections[i].Cookies))
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		target, err := rowID(tx, "collections", targetCollectionID, "collection not found")
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM folders WHERE collection_id = ? AND parent_id IS NULL`, target)
+		if err != nil {
+			return err
+		}
+		if err := insertFolders(tx, target, nil, []Folder{folder}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE collections SET cookies = ? WHERE id = ?`, cookies, target); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE folders SET position = ? WHERE uid = ?`, position, folder.ID)
+		return err
+	})
+}
+
+// rowUID lets shiftByID reorder plain uid lists loaded from a table.
+type rowUID string
+
+func (r rowUID) itemID() string { return string(r) }
+
+// shiftRow moves a row by delta places among the rows matching scope and
+// renumbers their positions.
+func shiftRow(tx *sql.Tx, table string, scope string, uid string, delta int, args ...interface{}) error {
+	rows, err := tx.Query(`SELECT uid FROM `+table+` WHERE `+scope+` ORDER BY position, id`, args...)
+	if err != nil {
+		return err
+	}
+	var uids []rowUID
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		uids = append(uids, rowUID(id))
+	}
+	rows.Close()
+	if err := shiftByID(uids, uid, delta); err != nil {
+		return err
+	}
+	for position, id := range uids {
+		if _, err := tx.Exec(`UPDATE `+table+` SET position = ? WHERE uid = ?`, position, string(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullableID turns a nullable row reference back into a query argument.
+func nullableID(id sql.NullInt64) interface{} {
+	if id.Valid {
+		return id.Int64
+	}
+	return nil
+}
+
+// exec runs a single-row statement and reports notFound when nothing matched.
+func (s *sqliteStore) exec(query string, notFound string, args ...interface{}) error {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s", notFound)
+	}
+	return nil
+}
+
+// placement resolves a collection and optional folder to row IDs; folder is
+// nil for the collection root.
+func placement(tx *sql.Tx, collectionID string, folderID string) (int64, interface{}, error) {
+	collection, err := rowID(tx, "collections", collectionID, "collection not found")
+	if err != nil {
+		return 0, nil, err
+	}
+	if folderID == "" {
+		return collection, nil, nil
+	}
+	var folder int64
+	err = tx.QueryRow(`SELECT id FROM folders WHERE uid = ? AND collection_id = ?`, folderID, collection).Scan(&folder)
+	if err == sql.ErrNoRows {
+		return 0, nil, fmt.Errorf("folder not found")
+	}
+	return collection, folder, err
+}
+
+func (s *sqliteStore) AddFolder(collectionID string, parentID string, name string) error {
+	if name == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		collection, parent, err := placement(tx, collectionID, parentID)
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM folders WHERE collection_id = ? AND parent_id IS ?`, collection, parent)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO folders(uid, collection_id, parent_id, position, name, data) VALUES (?, ?, ?, ?, ?, '{}')`,
+			newID(), collection, parent, position, name)
+		return err
+	})
+}
+
+func (s *sqliteStore) RenameFolder(folderID string, name string) error {
+	if name == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	return s.exec(`UPDATE folders SET name = ? WHERE uid = ?`, "folder not found", name, folderID)
+}
+
+func (s *sqliteStore) SetFolderAuth(folderID string, auth *Auth) error {
+	return s.updateFolder(folderID, func(folder *folderData) error {
+		folder.Auth = auth
+		return nil
+	})
+}
+
+func (s *sqliteStore) DeleteFolder(folderID string) error {
+	return s.exec(`DELETE FROM folders WHERE uid = ?`, "folder not found", folderID)
+}
+
+func (s *sqliteStore) ReorderFolder(folderID string, delta int) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var collection int64
+		var parent sql.NullInt64
+		err := tx.QueryRow(`SELECT collection_id, parent_id FROM folders WHERE uid = ?`, folderID).Scan(&collection, &parent)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("folder not found")
+		}
+		if err != nil {
+			return err
+		}
+		return shiftRow(tx, "folders", "collection_id = ? AND parent_id IS ?", folderID, delta, collection, nullableID(parent))
+	})
+}
+
+func (s *sqliteStore) updateFolder(folderID string, fn func(folder *folderData) error) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var data string
+		err := tx.QueryRow(`SELECT data FROM folders WHERE uid = ?`, folderID).Scan(&data)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("folder not found")
+		}
+		if err != nil {
+			return err
+		}
+		var folder folderData
+		if err := json.Unmarshal([]byte(data), &folder); err != nil {
+			return fmt.Errorf("failed to parse folder: %w", err)
+		}
+		if err := fn(&folder); err != nil {
+			return err
+		}
+		updated, err := json.Marshal(folder)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE folders SET data = ? WHERE uid = ?`, string(updated), folderID)
+		return err
+	})
+}
+
+func (s *sqliteStore) isFolder(id string) bool {
+	var exists int
+	return s.db.QueryRow(`SELECT 1 FROM folders WHERE uid = ?`, id).Scan(&exists) == nil
+}
+
+func (s *sqliteStore) AddRequest(collectionID string, folderID string, api Api) error {
+	if err := validateApi(api); err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		collection, folder, err := placement(tx, collectionID, folderID)
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM requests WHERE collection_id = ? AND folder_id IS ?`, collection, folder)
+		if err != nil {
+			return err
+		}
+		return insertApi(tx, collection, folder, position, api)
+	})
+}
+
+func (s *sqliteStore) MoveRequest(apiID string, collectionID string, folderID string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		collection, folder, err := placement(tx, collectionID, folderID)
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM requests WHERE collection_id = ? AND folder_id IS ?`, collection, folder)
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec(`UPDATE requests SET collection_id = ?, folder_id = ?, position = ? WHERE uid = ?`, collection, folder, position, apiID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("request not found")
+		}
+		return nil
+	})
+}
+
+func (s *sqliteStore) CopyRequest(apiID string, collectionID string, folderID string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		api, _, _, _, err := requestRow(tx, apiID)
+		if err != nil {
+			return err
+		}
+		collection, folder, err := placement(tx, collectionID, folderID)
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM requests WHERE collection_id = ? AND folder_id IS ?`, collection, folder)
+		if err != nil {
+			return err
+		}
+		return insertApi(tx, collection, folder, position, freshApi(api))
+	})
+}
+
+func (s *sqliteStore) DuplicateRequest(apiID string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		api, collection, folder, position, err := requestRow(tx, apiID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE requests SET position = position + 1 WHERE collection_id = ? AND folder_id IS ? AND position > ?`,
+			collection, folder, position); err != nil {
+			return err
+		}
+		duplicate := freshApi(api)
+		duplicate.Name = copyName(duplicate.Name)
+		return insertApi(tx, collection, folder, position+1, duplicate)
+	})
+}
+
+func (s *sqliteStore) ReorderRequest(apiID string, delta int) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		_, collection, folder, _, err := requestRow(tx, apiID)
+		if err != nil {
+			return err
+		}
+		return shiftRow(tx, "requests", "collection_id = ? AND folder_id IS ?", apiID, delta, collection, folder)
+	})
+}
+
+// requestRow loads a request with its collection, folder and position.
+func requestRow(tx *sql.Tx, apiID string) (Api, int64, interface{}, int, error) {
+	var api Api
+	var collection int64
+	var folder sql.NullInt64
+	var position int
+	var method, url, data string
+	err := tx.QueryRow(`SELECT collection_id, folder_id, position, method, url, data FROM requests WHERE uid = ?`, apiID).
+		Scan(&collection, &folder, &position, &method, &url, &data)
+	if err == sql.ErrNoRows {
+		return api, 0, nil, 0, fmt.Errorf("request not found")
+	}
+	if err != nil {
+		return api, 0, nil, 0, err
+	}
+	if err := json.Unmarshal([]byte(data), &api); err != nil {
+		return api, 0, nil, 0, fmt.Errorf("failed to parse request %s %s: %w", method, url, err)
+	}
+	api.ID = apiID
+	api.Method = method
+	api.Url = url
+	return api, collection, nullableID(folder), position, nil
+}
+
+func (s *sqliteStore) UpdateRequest(api Api) error {
+	if err := validateApi(api); err != nil {
+		return err
+	}
+	return s.updateApi(api.ID, func(existing *Api) error {
+		*existing = api
+		return nil
+	})
+}
+
+func (s *sqliteStore) DeleteRequest(apiID string) error {
+	return s.exec(`DELETE FROM requests WHERE uid = ?`, "request not found", apiID)
+}
+
+// updateApi rewrites a single request row instead of the whole workspace.
+func (s *sqliteStore) updateApi(apiID string, fn func(api *Api) error) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		var method, url, data string
+		err := tx.QueryRow(`SELECT method, url, data FROM requests WHERE uid = ?`, apiID).Scan(&method, &url, &data)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("request not found")
+		}
+		if err != nil {
+			return err
+		}
+		var api Api
+		if err := json.Unmarshal([]byte(data), &api); err != nil {
+			return fmt.Errorf("failed to parse request: %w", err)
+		}
+		api.Method = method
+		api.Url = url
+		if err := fn(&api); err != nil {
+			return err
+		}
+		api.ID = apiID
+		ensureApiIDs(&api)
+		updated, err := json.Marshal(api)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE requests SET method = ?, url = ?, data = ? WHERE uid = ?`, api.Method, api.Url, string(updated), apiID)
+		return err
+	})
+}
+
+func (s *sqliteStore) updateHeaders(ownerID string, fn func(headers *[]Header) error) error {
+	if s.isFolder(ownerID) {
+		return s.updateFolder(ownerID, func(folder *folderData) error {
+			return fn(&folder.Headers)
+		})
+	}
+	return s.updateApi(ownerID, func(api *Api) error {
+		return fn(&api.Headers)
+	})
+}
+
+func (s *sqliteStore) AddHeader(ownerID string, header Header) error {
+	return s.updateHeaders(ownerID, func(headers *[]Header) error {
+		header.ID = newID()
+		*headers = append(*headers, header)
+		return nil
+	})
+}
+
+func (s *sqliteStore) UpdateHeader(ownerID string, header Header) error {
+	return s.updateHeaders(ownerID, func(headers *[]Header) error {
+		return replaceByID(*headers, header)
+	})
+}
+
+func (s *sqliteStore) DeleteHeader(ownerID string, headerID string) error {
+	return s.updateHeaders(ownerID, func(headers *[]Header) error {
+		return removeByID(headers, headerID)
+	})
+}
+
+func (s *sqliteStore) AddQueryParam(apiID string, param QueryParam) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		param.ID = newID()
+		api.QueryParams = append(api.QueryParams, param)
+		return nil
+	})
+}
+
+func (s *sqliteStore) UpdateQueryParam(apiID string, param QueryParam) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return replaceByID(api.QueryParams, param)
+	})
+}
+
+func (s *sqliteStore) DeleteQueryParam(apiID string, paramID string) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return removeByID(&api.QueryParams, paramID)
+	})
+}
+
+func (s *sqliteStore) AddBodyField(apiID string, field BodyField) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		field.ID = newID()
+		api.BodyField = append(api.BodyField, field)
+		return nil
+	})
+}
+
+func (s *sqliteStore) UpdateBodyField(apiID string, field BodyField) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return replaceByID(api.BodyField, field)
+	})
+}
+
+func (s *sqliteStore) DeleteBodyField(apiID string, fieldID string) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return removeByID(&api.BodyField, fieldID)
+	})
+}
+
+func (s *sqliteStore) AddVariable(ownerID string, variable LocalVariable) error {
+	if s.isFolder(ownerID) {
+		return s.updateFolder(ownerID, func(folder *folderData) error {
+			variable.ID = newID()
+			folder.LocalVariables = append(folder.LocalVariables, variable)
+			return nil
+		})
+	}
+	collectionID := ownerID
+	return s.inTx(func(tx *sql.Tx) error {
+		collection, err := rowID(tx, "collections", collectionID, "collection not found")
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, `SELECT COALESCE(MAX(position), -1) + 1 FROM environment_variables WHERE collection_id = ?`, collection)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO environment_variables(uid, collection_id, position, key, value, enabled) VALUES (?, ?, ?, ?, ?, ?)`,
+			newID(), collection, position, variable.Key, variable.Value, variable.isEnabled())
+		return err
+	})
+}
+
+func (s *sqliteStore) UpdateVariable(ownerID string, variable LocalVariable) error {
+	if s.isFolder(ownerID) {
+		return s.updateFolder(ownerID, func(folder *folderData) error {
+			return replaceByID(folder.LocalVariables, variable)
+		})
+	}
+	collectionID := ownerID
+	return s.exec(`UPDATE environment_variables SET key = ?, value = ?, enabled = ?
+		WHERE uid = ? AND collection_id = (SELECT id FROM collections WHERE uid = ?)`,
+		"variable not found", variable.Key, variable.Value, variable.isEnabled(), variable.ID, collectionID)
+}
+
+func (s *sqliteStore) DeleteVariable(ownerID string, variableID string) error {
+	if s.isFolder(ownerID) {
+		return s.updateFolder(ownerID, func(folder *folderData) error {
+			return removeByID(&folder.LocalVariables, variableID)
+		})
+	}
+	collectionID := ownerID
+	return s.exec(`DELETE FROM environment_variables
+		WHERE uid = ? AND collection_id = (SELECT id FROM collections WHERE uid = ?)`,
+		"variable not found", variableID, collectionID)
+}
+
+// RecordHistory saves a response, with its body cut to maxHistoryBody, and
+// prunes the oldest ones beyond maxHistoryPerRequest for the request and
+// maxHistoryEntries in all.
+func (s *sqliteStore) RecordHistory(apiID string, entry HistoryEntry) error {
+	headers, err := json.Marshal(entry.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	if len(entry.ResponseBody) > maxHistoryBody {
+		entry.ResponseBody = entry.ResponseBody[:runeStart(entry.ResponseBody, maxHistoryBody)]
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO history(request_id, sent_at, method, url, status_code, status, response_headers, response_body)
+			VALUES ((SELECT id FROM requests WHERE uid = ?), ?, ?, ?, ?, ?, ?, ?)`,
+			apiID, entry.SentAt.UnixMilli(), entry.Method, entry.Url, entry.StatusCode, entry.Status, string(headers), entry.ResponseBody); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM history WHERE request_id = (SELECT id FROM requests WHERE uid = ?)
+			AND id NOT IN (SELECT h.id FROM history h JOIN requests r ON r.id = h.request_id WHERE r.uid = ? ORDER BY h.sent_at DESC, h.id DESC LIMIT ?)`,
+			apiID, apiID, maxHistoryPerRequest); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM history WHERE id NOT IN (SELECT id FROM history ORDER BY sent_at DESC, id DESC LIMIT ?)`, maxHistoryEntries)
+		return err
+	})
+}
+
+// SearchHistory returns the newest entries whose URL or body match query,
+// or the newest entries of all when query is blank.
+func (s *sqliteStore) SearchHistory(query string, limit int) ([]HistoryEntry, error) {
+	columns := `SELECT h.sent_at, h.method, h.url, h.status_code, h.status, h.response_headers, h.response_body`
+	var rows *sql.Rows
+	var err error
+	if strings.TrimSpace(query) == "" {
+		rows, err = s.db.Query(columns+` FROM history h ORDER BY h.sent_at DESC, h.id DESC LIMIT ?`, limit)
+	} else {
+		rows, err = s.db.Query(columns+` FROM history_search JOIN history h ON h.id = history_search.rowid
+			WHERE history_search MATCH ? ORDER BY h.sent_at DESC, h.id DESC LIMIT ?`, ftsQuery(query), limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var entry HistoryEntry
+		var sentAt int64
+		var headers string
+		if err := rows.Scan(&sentAt, &entry.Method, &entry.Url, &entry.StatusCode, &entry.Status, &headers, &entry.ResponseBody); err != nil {
+			return nil, fmt.Errorf("failed to search history: %w", err)
+		}
+		entry.SentAt = time.UnixMilli(sentAt)
+		json.Unmarshal([]byte(headers), &entry.ResponseHeaders)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *sqliteStore) SearchRequests(query string, limit int) ([]Api, error) {
+	rows, err := s.db.Query(`SELECT r.uid, r.method, r.url, r.data
+		FROM requests_search JOIN requests r ON r.id = requests_search.rowid
+		WHERE requests_search MATCH ? ORDER BY rank LIMIT ?`, ftsQuery(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search requests: %w", err)
+	}
+	defer rows.Close()
+
+	var apis []Api
+	for rows.Next() {
+		var uid, method, url, data string
+		if err := rows.Scan(&uid, &method, &url, &data); err != nil {
+			return nil, fmt.Errorf("failed to search requests: %w", err)
+		}
+		var api Api
+		if err := json.Unmarshal([]byte(data), &api); err != nil {
+			return nil, fmt.Errorf("failed to parse request %s %s: %w", method, url, err)
+		}
+		api.ID = uid
+		api.Method = method
+		api.Url = url
+		apis = append(apis, api)
+	}
+	return apis, rows.Err()
+}
+
+// ftsQuery turns free text into prefix terms so "user ord" matches "/users/orders".
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(query, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		terms = append(terms, `"`+word+`"*`)
+	}
+	if len(terms) == 0 {
+		return `""`
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func openSQLite(t *testing.T) *sqliteStore {
+	t.Helper()
+	return storeBackends[1].open(t).(*sqliteStore)
+}
+
+// loadCounter counts how often the whole workspace is read.
+type loadCounter struct {
+	Store
+	loads int
+}
+
+func (s *loadCounter) Load() (Storage, error) {
+	s.loads++
+	return s.Store.Load()
+}
+
+func TestEditsReloadOnlyTheirCollection(t *testing.T) {
+	store := &loadCounter{Store: openSQLite(t)}
+	m := NewModel(store, mustLoad(t, store))
+	m = press(m, runes(":"), runes("Pets"), keyEnter, keyEnter)
+
+	store.loads = 0
+	m = press(m, runes("n"), runes("Admin"), keyEnter)
+	if len(m.SelectedCollection.Folders) != 1 {
+		t.Fatalf("folders = %+v", m.SelectedCollection.Folders)
+	}
+	if store.loads != 0 {
+		t.Errorf("adding a folder loaded the workspace %d times", store.loads)
+	}
+}
+
+func TestSQLiteRestoreKeepsHistory(t *testing.T) {
+	store := openSQLite(t)
+	must(t, store.AddCollection("Pets"))
+	must(t, store.AddCollection("Users"))
+	storage := mustLoad(t, store)
+	pets, users := storage.Collections[0], storage.Collections[1]
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+	before := mustLoad(t, store)
+	api := before.Collections[0].Requests[0]
+	must(t, store.RecordHistory(api.ID, HistoryEntry{SentAt: time.Now(), Method: "GET", Url: "/pets"}))
+
+	// Undo a move of the request from Pets to Users.
+	must(t, store.MoveRequest(api.ID, users.ID, ""))
+	must(t, store.RestoreCollections(before.Collections, nil))
+
+	restored := mustLoad(t, store)
+	if len(restored.Collections[0].Requests) != 1 || len(restored.Collections[1].Requests) != 0 {
+		t.Fatalf("restored = %+v", restored.Collections)
+	}
+	var linked int
+	must(t, store.db.QueryRow(`SELECT COUNT(*) FROM history h JOIN requests r ON r.id = h.request_id WHERE r.uid = ?`, api.ID).Scan(&linked))
+	if linked != 1 {
+		t.Errorf("%d history entries linked to the request, want 1", linked)
+	}
+}
+
+func TestSQLiteSearchRequests(t *testing.T) {
+	store := openSQLite(t)
+	must(t, store.AddCollection("Pets"))
+	pets := collectionNamed(t, mustLoad(t, store), "Pets")
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "https://api.example.com/v1/items", Name: "List pets", Description: "Returns every animal in the shelter"}))
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "POST", Url: "https://api.example.com/v1/orders"}))
+
+	search := func(query string) []string {
+		t.Helper()
+		apis, err := store.SearchRequests(query, 10)
+		must(t, err)
+		var urls []string
+		for _, api := range apis {
+			urls = append(urls, api.Url)
+		}
+		return urls
+	}
+	for _, query := range []string{"pets", "anim", "shelter", "items"} {
+		if got := search(query); len(got) != 1 || got[0] != "https://api.example.com/v1/items" {
+			t.Errorf("SearchRequests(%q) = %v", query, got)
+		}
+	}
+
+	api := collectionNamed(t, mustLoad(t, store), "Pets").Requests[0]
+	api.Name = "All dogs"
+	must(t, store.UpdateRequest(api))
+	if got := search("pets"); len(got) != 0 {
+		t.Errorf("old name still found: %v", got)
+	}
+	if got := search("dogs"); len(got) != 1 {
+		t.Errorf("new name not found: %v", got)
+	}
+
+	must(t, store.DeleteRequest(api.ID))
+	if got := search("dogs"); len(got) != 0 {
+		t.Errorf("deleted request still found: %v", got)
+	}
+}
+
+func TestSearchRequestsRanksIndexFirst(t *testing.T) {
+	store := openSQLite(t)
+	must(t, store.AddCollection("Pets"))
+	pets := collectionNamed(t, mustLoad(t, store), "Pets")
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/a", Name: "owners", Description: "pets of an owner"}))
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+
+	entries := finderEntries(mustLoad(t, store))
+	ranked, err := store.SearchRequests("pets", 10)
+	must(t, err)
+	results := searchRequests(store, entries, "pets")
+	if len(results) != 2 || len(ranked) != 2 {
+		t.Fatalf("results = %+v, index = %+v", results, ranked)
+	}
+	for i := range ranked {
+		if results[i].Api.ID != ranked[i].ID {
+			t.Errorf("result %d = %s, want the index order %s", i, results[i].Api.Url, ranked[i].Url)
+		}
+	}
+
+	// A typo the index misses still finds the request through fuzzy matching.
+	if got := searchRequests(store, entries, "pts"); len(got) != 2 {
+		t.Errorf("fuzzy fallback = %+v", got)
+	}
+}
+
+func TestSQLiteHistory(t *testing.T) {
+	store := openSQLite(t)
+	must(t, store.AddCollection("Pets"))
+	pets := collectionNamed(t, mustLoad(t, store), "Pets")
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+	api := collectionNamed(t, mustLoad(t, store), "Pets").Requests[0]
+
+	start := time.Now()
+	for i := range maxHistoryPerRequest + 5 {
+		must(t, store.RecordHistory(api.ID, HistoryEntry{
+			SentAt: start.Add(time.Duration(i) * time.Second), Method: "GET", Url: "/pets",
+			StatusCode: 200, Status: "200 OK", ResponseBody: fmt.Sprintf(`{"call":"number%d"}`, i),
+		}))
+	}
+
+	entries, err := store.SearchHistory("", 1000)
+	must(t, err)
+	if len(entries) != maxHistoryPerRequest {
+		t.Fatalf("kept %d entries, want %d", len(entries), maxHistoryPerRequest)
+	}
+	if newest := entries[0].ResponseBody; newest != fmt.Sprintf(`{"call":"number%d"}`, maxHistoryPerRequest+4) {
+		t.Errorf("newest entry = %s", newest)
+	}
+
+	if found, err := store.SearchHistory("number10", 10); err != nil || len(found) != 1 {
+		t.Errorf("search for a kept body = %d entries, %v", len(found), err)
+	}
+	if found, err := store.SearchHistory("number0", 10); err != nil || len(found) != 0 {
+		t.Errorf("pruned entry still found: %d entries, %v", len(found), err)
+	}
+}
+
+func TestSQLiteHistoryCapsBodies(t *testing.T) {
+	store := openSQLite(t)
+	must(t, store.AddCollection("Pets"))
+	pets := collectionNamed(t, mustLoad(t, store), "Pets")
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+	api := collectionNamed(t, mustLoad(t, store), "Pets").Requests[0]
+
+	body := strings.Repeat("ü", maxHistoryBody)
+	must(t, store.RecordHistory(api.ID, HistoryEntry{SentAt: time.Now(), Method: "GET", Url: "/pets", ResponseBody: body}))
+	entries, err := store.SearchHistory("", 1)
+	must(t, err)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	stored := entries[0].ResponseBody
+	if len(stored) > maxHistoryBody || !utf8.ValidString(stored) || !strings.HasPrefix(body, stored) {
+		t.Errorf("stored %d bytes, valid UTF-8 %v", len(stored), utf8.ValidString(stored))
+	}
+}
+
+func TestSQLiteSearchNamesMigration(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := fileName
+	fileName = filepath.Join(dir, "missing.json")
+	t.Cleanup(func() { fileName = oldFile })
+	path := filepath.Join(dir, "store.db")
+
+	store, err := NewSQLiteStore(path)
+	must(t, err)
+	must(t, store.AddCollection("Pets"))
+	pets := collectionNamed(t, mustLoad(t, store), "Pets")
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/v1/items", Name: "List pets"}))
+
+	// Put the database back to the schema before names were indexed, the
+	// second to last step.
+	for _, statement := range []string{
+		`DROP TRIGGER requests_search_insert`,
+		`DROP TRIGGER requests_search_delete`,
+		`DROP TRIGGER requests_search_update`,
+		`DROP TABLE requests_search`,
+		sqliteSchema,
+		`INSERT INTO requests_search(rowid, method, url) SELECT id, method, url FROM requests`,
+		fmt.Sprintf(`PRAGMA user_version = %d`, len(sqliteMigrations)-1),
+	} {
+		_, err := store.db.Exec(statement)
+		must(t, err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteStore(path)
+	must(t, err)
+	defer store.Close()
+	if apis, err := store.SearchRequests("pets", 10); err != nil || len(apis) != 1 {
+		t.Errorf("SearchRequests after migration = %+v, %v", apis, err)
+	}
+}
+
+func TestSQLiteDisabledFlagMigration(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := fileName
+	fileName = filepath.Join(dir, "missing.json")
+	t.Cleanup(func() { fileName = oldFile })
+	path := filepath.Join(dir, "store.db")
+
+	store, err := NewSQLiteStore(path)
+	must(t, err)
+	must(t, store.AddCollection("Pets"))
+	pets := collectionNamed(t, mustLoad(t, store), "Pets")
+	must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+
+	// Store the items the way the enabled flag did.
+	for _, statement := range []string{
+		`UPDATE requests SET data = json_set(data, '$.headers', json('[{"id": "on", "key": "A", "value": "1", "enabled": true}, {"id": "off", "key": "B", "value": "2", "enabled": false}]'))`,
+		fmt.Sprintf(`PRAGMA user_version = %d`, len(sqliteMigrations)),
+	} {
+		_, err := store.db.Exec(statement)
+		must(t, err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteStore(path)
+	must(t, err)
+	defer store.Close()
+	headers := collectionNamed(t, mustLoad(t, store), "Pets").Requests[0].Headers
+	if len(headers) != 2 || headers[0].Disabled || !headers[1].Disabled {
+		t.Errorf("headers after migration = %+v", headers)
+	}
+}
+
+func TestHistoryPage(t *testing.T) {
+	store := openSQLite(t)
+	must(t, store.RecordHistory("", HistoryEntry{SentAt: time.Now(), Method: "GET", Url: "/pets", Status: "200 OK", ResponseBody: `{"name":"rex"}`}))
+	must(t, store.RecordHistory("", HistoryEntry{SentAt: time.Now(), Method: "GET", Url: "/owners", Status: "200 OK", ResponseBody: `{"name":"ann"}`}))
+	m := NewModel(store, mustLoad(t, store))
+
+	m, _ = UpdateHomePage(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	if m.CurrentPage != HistoryPage || len(m.historyEntries) != 2 {
+		t.Fatalf("page %v with %d entries", m.CurrentPage, len(m.historyEntries))
+	}
+	m, _ = UpdateHistoryPage(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rex")})
+	if len(m.historyEntries) != 1 || m.historyEntries[0].Url != "/pets" {
+		t.Errorf("entries for rex = %+v", m.historyEntries)
+	}
+
+	m = NewModel(NewMemoryStore(Storage{}), Storage{})
+	if m, _ = UpdateHomePage(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")}); m.CurrentPage == HistoryPage {
+		t.Error("opened history without a store that keeps it")
+	}
+}
@@ -0,0 +1,816 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Store interface {
+	Load() (Storage, error)
+	// LoadCollection reads one collection, so an edit inside it does not
+	// reload the whole workspace.
+	LoadCollection(collectionID string) (Collection, error)
+	// RestoreCollections writes collections back as they were, for undo:
+	// each replaces the collection with its ID, keeping that one's cookie
+	// jar, or is added when it is gone. A non-nil order then lists every
+	// collection that stays, in order; the others are deleted.
+	RestoreCollections(collections []Collection, order []string) error
+
+	AddCollection(name string) error
+	RenameCollection(collectionID string, name string) error
+	SetCollectionDescription(collectionID string, description string) error
+	// SetCookies replaces the cookie jar of a collection.
+	SetCookies(collectionID string, cookies []Cookie) error
+	SetCollectionSettings(collectionID string, settings CollectionSettings) error
+	SetGlobalSettings(settings GlobalSettings) error
+	DeleteCollection(collectionID string) error
+	DuplicateCollection(collectionID string) error
+	// ReorderCollection, ReorderFolder and ReorderRequest shift an item by
+	// delta places among its siblings.
+	ReorderCollection(collectionID string, delta int) error
+	// MoveCollection and CopyCollection nest a collection inside another one
+	// as a folder; its variables become the folder's variables.
+	MoveCollection(collectionID string, targetCollectionID string) error
+	CopyCollection(collectionID string, targetCollectionID string) error
+
+	// An empty parentID or folderID means the collection root.
+	AddFolder(collectionID string, parentID string, name string) error
+	RenameFolder(folderID string, name string) error
+	SetFolderAuth(folderID string, auth *Auth) error
+	DeleteFolder(folderID string) error
+	ReorderFolder(folderID string, delta int) error
+
+	AddRequest(collectionID string, folderID string, api Api) error
+	UpdateRequest(api Api) error
+	MoveRequest(apiID string, collectionID string, folderID string) error
+	CopyRequest(apiID string, collectionID string, folderID string) error
+	DuplicateRequest(apiID string) error
+	ReorderRequest(apiID string, delta int) error
+	DeleteRequest(apiID string) error
+
+	// Headers belong to a request or a folder.
+	AddHeader(ownerID string, header Header) error
+	UpdateHeader(ownerID string, header Header) error
+	DeleteHeader(ownerID string, headerID string) error
+
+	AddQueryParam(apiID string, param QueryParam) error
+	UpdateQueryParam(apiID string, param QueryParam) error
+	DeleteQueryParam(apiID string, paramID string) error
+
+	AddBodyField(apiID string, field BodyField) error
+	UpdateBodyField(apiID string, field BodyField) error
+	DeleteBodyField(apiID string, fieldID string) error
+
+	// Variables belong to a collection or a folder.
+	AddVariable(ownerID string, variable LocalVariable) error
+	UpdateVariable(ownerID string, variable LocalVariable) error
+	DeleteVariable(ownerID string, variableID string) error
+}
+
+// document is a backend that loads and saves the whole Storage at once.
+type document interface {
+	read() (Storage, error)
+	write(storage Storage) error
+}
+
+type documentStore struct {
+	doc document
+}
+
+func NewFileStore() Store {
+	return &documentStore{doc: jsonDocument{}}
+}
+
+func NewMemoryStore(storage Storage) Store {
+	return &documentStore{doc: &memoryDocument{storage: cloneStorage(storage)}}
+}
+
+type jsonDocument struct{}
+
+func (jsonDocument) read() (Storage, error) {
+	return ReadFile()
+}
+
+func (jsonDocument) write(storage Storage) error {
+	return WriteFile(storage)
+}
+
+type memoryDocument struct {
+	storage Storage
+}
+
+func (d *memoryDocument) read() (Storage, error) {
+	return cloneStorage(d.storage), nil
+}
+
+func (d *memoryDocument) write(storage Storage) error {
+	storage.Version = storageVersion
+	d.storage = cloneStorage(storage)
+	return nil
+}
+
+func cloneStorage(storage Storage) Storage {
+	data, err := json.Marshal(storage)
+	if err != nil {
+		return storage
+	}
+	var clone Storage
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return storage
+	}
+	return clone
+}
+
+// freshApi deep-copies a request and clears every ID so the copy gets its own.
+func freshApi(api Api) Api {
+	var copied Api
+	data, _ := json.Marshal(api)
+	json.Unmarshal(data, &copied)
+	copied.ID = ""
+	clearApiIDs(&copied)
+	return copied
+}
+
+func clearApiIDs(api *Api) {
+	api.ID = ""
+	for i := range api.Headers {
+		api.Headers[i].ID = ""
+	}
+	for i := range api.QueryParams {
+		api.QueryParams[i].ID = ""
+	}
+	for i := range api.BodyField {
+		api.BodyField[i].ID = ""
+	}
+	for i := range api.PathParams {
+		api.PathParams[i].ID = ""
+	}
+}
+
+func clearFolderIDs(folder *Folder) {
+	folder.ID = ""
+	for i := range folder.Headers {
+		folder.Headers[i].ID = ""
+	}
+	for i := range folder.LocalVariables {
+		folder.LocalVariables[i].ID = ""
+	}
+	for i := range folder.Requests {
+		clearApiIDs(&folder.Requests[i])
+	}
+	for i := range folder.Folders {
+		clearFolderIDs(&folder.Folders[i])
+	}
+}
+
+// collectionFolder turns a collection into a folder for nesting it inside
+// another collection. With fresh set every ID is cleared for a copy.
+func collectionFolder(collection Collection, fresh bool) Folder {
+	var folder Folder
+	data, _ := json.Marshal(Folder{
+		ID:             collection.ID,
+		Name:           collection.Name,
+		Description:    collection.Description,
+		Requests:       collection.Requests,
+		Folders:        collection.Folders,
+		LocalVariables: collection.LocalVariables,
+	})
+	json.Unmarshal(data, &folder)
+	if fresh {
+		clearFolderIDs(&folder)
+	}
+	return folder
+}
+
+// checkNestable refuses to nest a collection whose TLS, proxy or retry
+// settings would be lost, as folders have none of their own.
+func checkNestable(collection Collection, target Collection) error {
+	if collection.Settings != (CollectionSettings{}) && collection.Settings != target.Settings {
+		return fmt.Errorf("%s has its own TLS, proxy or retry settings, clear them before nesting it", collection.Name)
+	}
+	return nil
+}
+
+// mergeCookies adds a nested collection's cookies to the target's jar. The
+// target keeps its own cookie where both have one.
+func mergeCookies(target []Cookie, nested []Cookie) []Cookie {
+	jar := newCookieJar(target)
+	for _, cookie := range nested {
+		if jar.find(cookie) < 0 {
+			jar.cookies = append(jar.cookies, cookie)
+		}
+	}
+	return jar.cookies
+}
+
+func copyName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return name + " (copy)"
+}
+
+func (s *documentStore) Load() (Storage, error) {
+	storage, err := s.doc.read()
+	if err != nil {
+		return Storage{}, err
+	}
+	if ensureIDs(&storage) {
+		if err := s.doc.write(storage); err != nil {
+			return Storage{}, err
+		}
+	}
+	return storage, nil
+}
+
+func (s *documentStore) LoadCollection(collectionID string) (Collection, error) {
+	storage, err := s.Load()
+	if err != nil {
+		return Collection{}, err
+	}
+	i := indexByID(storage.Collections, collectionID)
+	if i < 0 {
+		return Collection{}, fmt.Errorf("collection not found")
+	}
+	return storage.Collections[i], nil
+}
+
+func (s *documentStore) RestoreCollections(collections []Collection, order []string) error {
+	return s.update(func(storage *Storage) error {
+		for _, collection := range collections {
+			if i := indexByID(storage.Collections, collection.ID); i >= 0 {
+				collection.Cookies = storage.Collections[i].Cookies
+				storage.Collections[i] = collection
+			} else {
+				storage.Collections = append(storage.Collections, collection)
+			}
+		}
+		if order == nil {
+			return nil
+		}
+		sorted := make([]Collection, 0, len(order))
+		for _, id := range order {
+			if i := indexByID(storage.Collections, id); i >= 0 {
+				sorted = append(sorted, storage.Collections[i])
+			}
+		}
+		storage.Collections = sorted
+		return nil
+	})
+}
+
+func (s *documentStore) update(fn func(storage *Storage) error) error {
+	storage, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&storage); err != nil {
+		return err
+	}
+	ensureIDs(&storage)
+	return s.doc.write(storage)
+}
+
+func (s *documentStore) updateCollection(collectionID string, fn func(collection *Collection) error) error {
+	return s.update(func(storage *Storage) error {
+		i := indexByID(storage.Collections, collectionID)
+		if i < 0 {
+			return fmt.Errorf("collection not found")
+		}
+		return fn(&storage.Collections[i])
+	})
+}
+
+func (s *documentStore) updateApi(apiID string, fn func(api *Api) error) error {
+	return s.update(func(storage *Storage) error {
+		api := findApi(storage, apiID)
+		if api == nil {
+			return fmt.Errorf("request not found")
+		}
+		return fn(api)
+	})
+}
+
+func (s *documentStore) updateFolder(folderID string, fn func(folder *Folder) error) error {
+	return s.update(func(storage *Storage) error {
+		folder := findStorageFolder(storage, folderID)
+		if folder == nil {
+			return fmt.Errorf("folder not found")
+		}
+		return fn(folder)
+	})
+}
+
+func findApi(storage *Storage, apiID string) *Api {
+	if list, i := locateApi(storage, apiID); list != nil {
+		return &(*list)[i]
+	}
+	return nil
+}
+
+// locateApi returns the slice holding the request, wherever it is nested.
+func locateApi(storage *Storage, apiID string) (*[]Api, int) {
+	var search func(folders []Folder) (*[]Api, int)
+	search = func(folders []Folder) (*[]Api, int) {
+		for i := range folders {
+			if j := indexByID(folders[i].Requests, apiID); j >= 0 {
+				return &folders[i].Requests, j
+			}
+			if list, j := search(folders[i].Folders); list != nil {
+				return list, j
+			}
+		}
+		return nil, -1
+	}
+	for i := range storage.Collections {
+		collection := &storage.Collections[i]
+		if j := indexByID(collection.Requests, apiID); j >= 0 {
+			return &collection.Requests, j
+		}
+		if list, j := search(collection.Folders); list != nil {
+			return list, j
+		}
+	}
+	return nil, -1
+}
+
+func findStorageFolder(storage *Storage, folderID string) *Folder {
+	for i := range storage.Collections {
+		if folder := findFolder(storage.Collections[i].Folders, folderID); folder != nil {
+			return folder
+		}
+	}
+	return nil
+}
+
+// folderList returns the slice that holds the folder, so it can be removed.
+func folderList(folders *[]Folder, folderID string) (*[]Folder, int) {
+	if i := indexByID(*folders, folderID); i >= 0 {
+		return folders, i
+	}
+	for i := range *folders {
+		if list, j := folderList(&(*folders)[i].Folders, folderID); list != nil {
+			return list, j
+		}
+	}
+	return nil, -1
+}
+
+func requestList(storage *Storage, collectionID string, folderID string) (*[]Api, error) {
+	i := indexByID(storage.Collections, collectionID)
+	if i < 0 {
+		return nil, fmt.Errorf("collection not found")
+	}
+	collection := &storage.Collections[i]
+	if folderID == "" {
+		return &collection.Requests, nil
+	}
+	folder := findFolder(collection.Folders, folderID)
+	if folder == nil {
+		return nil, fmt.Errorf("folder not found")
+	}
+	return &folder.Requests, nil
+}
+
+func (s *documentStore) AddCollection(name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	return s.update(func(storage *Storage) error {
+		storage.Collections = append(storage.Collections, Collection{ID: newID(), Name: name})
+		return nil
+	})
+}
+
+func (s *documentStore) RenameCollection(collectionID string, name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	return s.updateCollection(collectionID, func(collection *Collection) error {
+		collection.Name = name
+		return nil
+	})
+}
+
+func (s *documentStore) SetCollectionDescription(collectionID string, description string) error {
+	return s.updateCollection(collectionID, func(collection *Collection) error {
+		collection.Description = description
+		return nil
+	})
+}
+
+func (s *documentStore) SetCookies(collectionID string, cookies []Cookie) error {
+	return s.updateCollection(collectionID, func(collection *Collection) error {
+		collection.Cookies = cookies
+		return nil
+	})
+}
+
+func (s *documentStore) SetCollectionSettings(collectionID string, settings CollectionSettings) error {
+	return s.updateCollection(collectionID, func(collection *Collection) error {
+		collection.Settings = settings
+		return nil
+	})
+}
+
+func (s *documentStore) SetGlobalSettings(settings GlobalSettings) error {
+	return s.update(func(storage *Storage) error {
+		storage.Settings = settings
+		return nil
+	})
+}
+
+func (s *documentStore) DeleteCollection(collectionID string) error {
+	return s.update(func(storage *Storage) error {
+		return removeByID(&storage.Collections, collectionID)
+	})
+}
+
+func (s *documentStore) DuplicateCollection(collectionID string) error {
+	return s.update(func(storage *Storage) error {
+		i := indexByID(storage.Collections, collectionID)
+		if i < 0 {
+			return fmt.Errorf("collection not found")
+		}
+		folder := collectionFolder(storage.Collections[i], true)
+		duplicate := Collection{
+			Name:           copyName(storage.Collections[i].Name),
+			Description:    storage.Collections[i].Description,
+			Requests:       folder.Requests,
+			Folders:        folder.Folders,
+			LocalVariables: folder.LocalVariables,
+			Settings:       storage.Collections[i].Settings,
+		}
+		storage.Collections = append(storage.Collections[:i+1], append([]Collection{duplicate}, storage.Collections[i+1:]...)...)
+		return nil
+	})
+}
+
+func (s *documentStore) ReorderCollection(collectionID string, delta int) error {
+	return s.update(func(storage *Storage) error {
+		return shiftByID(storage.Collections, collectionID, delta)
+	})
+}
+
+func (s *documentStore) MoveCollection(collectionID string, targetCollectionID string) error {
+	return s.nestCollection(collectionID, targetCollectionID, false)
+}
+
+func (s *documentStore) CopyCollection(collectionID string, targetCollectionID string) error {
+	return s.nestCollection(collectionID, targetCollectionID, true)
+}
+
+func (s *documentStore) nestCollection(collectionID string, targetCollectionID string, fresh bool) error {
+	if collectionID == targetCollectionID {
+		return fmt.Errorf("cannot nest a collection inside itself")
+	}
+	return s.update(func(storage *Storage) error {
+		i := indexByID(storage.Collections, collectionID)
+		j := indexByID(storage.Collections, targetCollectionID)
+		if i < 0 || j < 0 {
+			return fmt.Errorf("collection not found")
+		}
+		target := &storage.Collections[j]
+		if err := checkNestable(storage.Collections[i], *target); err != nil {
+			return err
+		}
+		target.Folders = append(target.Folders, collectionFolder(storage.Collections[i], fresh))
+		target.Cookies = mergeCookies(target.Cookies, storage.Collections[i].Cookies)
+		if !fresh {
+			storage.Collections = append(storage.Collections[:i], storage.Collections[i+1:]...)
+		}
+		return nil
+	})
+}
+
+func (s *documentStore) AddFolder(collectionID string, parentID string, name string) error {
+	if name == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	return s.updateCollection(collectionID, func(collection *Collection) error {
+		folders := &collection.Folders
+		if parentID != "" {
+			parent := findFolder(collection.Folders, parentID)
+			if parent == nil {
+				return fmt.Errorf("folder not found")
+			}
+			folders = &parent.Folders
+		}
+		*folders = append(*folders, Folder{ID: newID(), Name: name})
+		return nil
+	})
+}
+
+func (s *documentStore) RenameFolder(folderID string, name string) error {
+	if name == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	return s.updateFolder(folderID, func(folder *Folder) error {
+		folder.Name = name
+		return nil
+	})
+}
+
+func (s *documentStore) SetFolderAuth(folderID string, auth *Auth) error {
+	return s.updateFolder(folderID, func(folder *Folder) error {
+		folder.Auth = auth
+		return nil
+	})
+}
+
+func (s *documentStore) DeleteFolder(folderID string) error {
+	return s.update(func(storage *Storage) error {
+		for i := range storage.Collections {
+			if list, j := folderList(&storage.Collections[i].Folders, folderID); list != nil {
+				*list = append((*list)[:j], (*list)[j+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("folder not found")
+	})
+}
+
+func (s *documentStore) ReorderFolder(folderID string, delta int) error {
+	return s.update(func(storage *Storage) error {
+		for i := range storage.Collections {
+			if list, _ := folderList(&storage.Collections[i].Folders, folderID); list != nil {
+				return shiftByID(*list, folderID, delta)
+			}
+		}
+		return fmt.Errorf("folder not found")
+	})
+}
+
+func (s *documentStore) AddRequest(collectionID string, folderID string, api Api) error {
+	if err := validateApi(api); err != nil {
+		return err
+	}
+	return s.update(func(storage *Storage) error {
+		list, err := requestList(storage, collectionID, folderID)
+		if err != nil {
+			return err
+		}
+		ensureApiIDs(&api)
+		*list = append(*list, api)
+		return nil
+	})
+}
+
+func (s *documentStore) UpdateRequest(api Api) error {
+	if err := validateApi(api); err != nil {
+		return err
+	}
+	return s.updateApi(api.ID, func(existing *Api) error {
+		ensureApiIDs(&api)
+		*existing = api
+		return nil
+	})
+}
+
+func (s *documentStore) MoveRequest(apiID string, collectionID string, folderID string) error {
+	return s.update(func(storage *Storage) error {
+		if _, err := requestList(storage, collectionID, folderID); err != nil {
+			return err
+		}
+		from, i := locateApi(storage, apiID)
+		if from == nil {
+			return fmt.Errorf("request not found")
+		}
+		api := (*from)[i]
+		*from = append((*from)[:i], (*from)[i+1:]...)
+
+		to, _ := requestList(storage, collectionID, folderID)
+		*to = append(*to, api)
+		return nil
+	})
+}
+
+func (s *documentStore) CopyRequest(apiID string, collectionID string, folderID string) error {
+	return s.update(func(storage *Storage) error {
+		api := findApi(storage, apiID)
+		if api == nil {
+			return fmt.Errorf("request not found")
+		}
+		copied := freshApi(*api)
+		to, err := requestList(storage, collectionID, folderID)
+		if err != nil {
+			return err
+		}
+		*to = append(*to, copied)
+		return nil
+	})
+}
+
+func (s *documentStore) DuplicateRequest(apiID string) error {
+	return s.update(func(storage *Storage) error {
+		list, i := locateApi(storage, apiID)
+		if list == nil {
+			return fmt.Errorf("request not found")
+		}
+		duplicate := freshApi((*list)[i])
+		duplicate.Name = copyName(duplicate.Name)
+		*list = append((*list)[:i+1], append([]Api{duplicate}, (*list)[i+1:]...)...)
+		return nil
+	})
+}
+
+func (s *documentStore) ReorderRequest(apiID string, delta int) error {
+	return s.update(func(storage *Storage) error {
+		list, _ := locateApi(storage, apiID)
+		if list == nil {
+			return fmt.Errorf("request not found")
+		}
+		return shiftByID(*list, apiID, delta)
+	})
+}
+
+func (s *documentStore) DeleteRequest(apiID string) error {
+	return s.update(func(storage *Storage) error {
+		list, i := locateApi(storage, apiID)
+		if list == nil {
+			return fmt.Errorf("request not found")
+		}
+		*list = append((*list)[:i], (*list)[i+1:]...)
+		return nil
+	})
+}
+
+func (s *documentStore) updateHeaders(ownerID string, fn func(headers *[]Header) error) error {
+	return s.update(func(storage *Storage) error {
+		if api := findApi(storage, ownerID); api != nil {
+			return fn(&api.Headers)
+		}
+		if folder := findStorageFolder(storage, ownerID); folder != nil {
+			return fn(&folder.Headers)
+		}
+		return fmt.Errorf("request or folder not found")
+	})
+}
+
+func (s *documentStore) AddHeader(ownerID string, header Header) error {
+	return s.updateHeaders(ownerID, func(headers *[]Header) error {
+		header.ID = newID()
+		*headers = append(*headers, header)
+		return nil
+	})
+}
+
+func (s *documentStore) UpdateHeader(ownerID string, header Header) error {
+	return s.updateHeaders(ownerID, func(headers *[]Header) error {
+		return replaceByID(*headers, header)
+	})
+}
+
+func (s *documentStore) DeleteHeader(ownerID string, headerID string) error {
+	return s.updateHeaders(ownerID, func(headers *[]Header) error {
+		return removeByID(headers, headerID)
+	})
+}
+
+func (s *documentStore) AddQueryParam(apiID string, param QueryParam) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		param.ID = newID()
+		api.QueryParams = append(api.QueryParams, param)
+		return nil
+	})
+}
+
+func (s *documentStore) UpdateQueryParam(apiID string, param QueryParam) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return replaceByID(api.QueryParams, param)
+	})
+}
+
+func (s *documentStore) DeleteQueryParam(apiID string, paramID string) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return removeByID(&api.QueryParams, paramID)
+	})
+}
+
+func (s *documentStore) AddBodyField(apiID string, field BodyField) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		field.ID = newID()
+		api.BodyField = append(api.BodyField, field)
+		return nil
+	})
+}
+
+func (s *documentStore) UpdateBodyField(apiID string, field BodyField) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return replaceByID(api.BodyField, field)
+	})
+}
+
+func (s *documentStore) DeleteBodyField(apiID string, fieldID string) error {
+	return s.updateApi(apiID, func(api *Api) error {
+		return removeByID(&api.BodyField, fieldID)
+	})
+}
+
+func (s *documentStore) updateVariables(ownerID string, fn func(variables *[]LocalVariable) error) error {
+	return s.update(func(storage *Storage) error {
+		if i := indexByID(storage.Collections, ownerID); i >= 0 {
+			return fn(&storage.Collections[i].LocalVariables)
+		}
+		if folder := findStorageFolder(storage, ownerID); folder != nil {
+			return fn(&folder.LocalVariables)
+		}
+		return fmt.Errorf("collection or folder not found")
+	})
+}
+
+func (s *documentStore) AddVariable(ownerID string, variable LocalVariable) error {
+	return s.updateVariables(ownerID, func(variables *[]LocalVariable) error {
+		variable.ID = newID()
+		*variables = append(*variables, variable)
+		return nil
+	})
+}
+
+func (s *documentStore) UpdateVariable(ownerID string, variable LocalVariable) error {
+	return s.updateVariables(ownerID, func(variables *[]LocalVariable) error {
+		return replaceByID(*variables, variable)
+	})
+}
+
+func (s *documentStore) DeleteVariable(ownerID string, variableID string) error {
+	return s.updateVariables(ownerID, func(variables *[]LocalVariable) error {
+		return removeByID(variables, variableID)
+	})
+}
+
+type identified interface {
+	itemID() string
+}
+
+func indexByID[T identified](items []T, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, item := range items {
+		if item.itemID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func replaceByID[T identified](items []T, item T) error {
+	i := indexByID(items, item.itemID())
+	if i < 0 {
+		return fmt.Errorf("item not found")
+	}
+	items[i] = item
+	return nil
+}
+
+func removeByID[T identified](items *[]T, id string) error {
+	i := indexByID(*items, id)
+	if i < 0 {
+		return fmt.Errorf("item not found")
+	}
+	*items = append((*items)[:i], (*items)[i+1:]...)
+	return nil
+}
+
+// shiftByID moves an item by delta places, stopping at either end.
+func shiftByID[T identified](items []T, id string, delta int) error {
+	i := indexByID(items, id)
+	if i < 0 {
+		return fmt.Errorf("item not found")
+	}
+	j := min(max(i+delta, 0), len(items)-1)
+	item := items[i]
+	if j > i {
+		copy(items[i:j], items[i+1:j+1])
+	} else {
+		copy(items[j+1:i+1], items[j:i])
+	}
+	items[j] = item
+	return nil
+}
+
+func validateApi(api Api) error {
+	if api.Method == "" {
+		return fmt.Errorf("method cannot be empty")
+	}
+	if api.Url == "" {
+		return fmt.Errorf("URL cannot be empty")
+	}
+	return nil
+}
+
+func parseApiInput(input string) (string, string, error) {
+	parts := strings.SplitN(input, " ", 2)
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid format: expected 'METHOD URL' (e.g., 'GET https://api.com')")
+	}
+	if parts[0] == "" {
+		return "", "", fmt.Errorf("method cannot be empty")
+	}
+	if parts[1] == "" {
+		return "", "", fmt.Errorf("URL cannot be empty")
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// storeBackends are the Store implementations every contract test runs on.
+var storeBackends = []struct {
+	name string
+	open func(t *testing.T) Store
+}{
+	{"memory", func(t *testing.T) Store {
+		return NewMemoryStore(Storage{Version: storageVersion, Collections: []Collection{}})
+	}},
+	{"sqlite", func(t *testing.T) Store {
+		dir := t.TempDir()
+		oldFile := fileName
+		fileName = filepath.Join(dir, "missing.json")
+		t.Cleanup(func() { fileName = oldFile })
+		store, err := NewSQLiteStore(filepath.Join(dir, "store.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	}},
+}
+
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	for _, backend := range storeBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			test(t, backend.open(t))
+		})
+	}
+}
+
+func mustLoad(t *testing.T, store Store) Storage {
+	t.Helper()
+	storage, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return storage
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func collectionNamed(t *testing.T, storage Storage, name string) Collection {
+	t.Helper()
+	for _, collection := range storage.Collections {
+		if collection.Name == name {
+			return collection
+		}
+	}
+	t.Fatalf("no collection %q in %+v", name, storage.Collections)
+	return Collection{}
+}
+
+func TestStoreCollections(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		must(t, store.AddCollection("Users"))
+		pets := collectionNamed(t, mustLoad(t, store), "Pets")
+		if pets.ID == "" {
+			t.Fatal("collection has no ID")
+		}
+
+		must(t, store.RenameCollection(pets.ID, "Animals"))
+		must(t, store.SetCollectionDescription(pets.ID, "All the animals"))
+		animals := collectionNamed(t, mustLoad(t, store), "Animals")
+		if animals.ID != pets.ID || animals.Description != "All the animals" {
+			t.Errorf("renamed collection = %+v", animals)
+		}
+
+		must(t, store.DeleteCollection(pets.ID))
+		storage := mustLoad(t, store)
+		if len(storage.Collections) != 1 || storage.Collections[0].Name != "Users" {
+			t.Errorf("collections after delete = %+v", storage.Collections)
+		}
+		if err := store.RenameCollection(pets.ID, "x"); err == nil {
+			t.Error("renamed a deleted collection")
+		}
+	})
+}
+
+func TestStoreLoadCollection(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		must(t, store.AddCollection("Users"))
+		pets := collectionNamed(t, mustLoad(t, store), "Pets")
+		must(t, store.AddFolder(pets.ID, "", "admin"))
+		must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+		must(t, store.AddVariable(pets.ID, LocalVariable{Key: "kind", Value: "cat"}))
+
+		loaded, err := store.LoadCollection(pets.ID)
+		must(t, err)
+		want := collectionNamed(t, mustLoad(t, store), "Pets")
+		if loaded.ID != want.ID || len(loaded.Folders) != 1 || len(loaded.Requests) != 1 || len(loaded.LocalVariables) != 1 {
+			t.Errorf("LoadCollection = %+v, want %+v", loaded, want)
+		}
+		if _, err := store.LoadCollection("missing"); err == nil {
+			t.Error("loaded a collection that does not exist")
+		}
+	})
+}
+
+func TestStoreRequestsAndFolders(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		pets := collectionNamed(t, mustLoad(t, store), "Pets")
+		must(t, store.AddFolder(pets.ID, "", "Admin"))
+		folder := collectionNamed(t, mustLoad(t, store), "Pets").Folders[0]
+
+		must(t, store.AddRequest(pets.ID, folder.ID, Api{Method: "GET", Url: "https://api.example.com/pets",
+			Headers: []Header{{Key: "Accept", Value: "application/json"}}}))
+		folder = collectionNamed(t, mustLoad(t, store), "Pets").Folders[0]
+		if len(folder.Requests) != 1 || folder.Requests[0].Headers[0].ID == "" {
+			t.Fatalf("folder requests = %+v", folder.Requests)
+		}
+		api := folder.Requests[0]
+
+		api.Name = "List pets"
+		must(t, store.UpdateRequest(api))
+		must(t, store.MoveRequest(api.ID, pets.ID, ""))
+		pets = collectionNamed(t, mustLoad(t, store), "Pets")
+		if len(pets.Folders[0].Requests) != 0 || len(pets.Requests) != 1 {
+			t.Fatalf("after move: root %+v, folder %+v", pets.Requests, pets.Folders[0].Requests)
+		}
+		if moved := pets.Requests[0]; moved.ID != api.ID || moved.Name != "List pets" {
+			t.Errorf("moved request = %+v", moved)
+		}
+
+		must(t, store.DeleteRequest(api.ID))
+		must(t, store.DeleteFolder(folder.ID))
+		pets = collectionNamed(t, mustLoad(t, store), "Pets")
+		if len(pets.Requests) != 0 || len(pets.Folders) != 0 {
+			t.Errorf("after delete: %+v", pets)
+		}
+	})
+}
+
+func TestStoreMoveCollection(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		must(t, store.AddCollection("Zoo"))
+		storage := mustLoad(t, store)
+		pets, zoo := collectionNamed(t, storage, "Pets"), collectionNamed(t, storage, "Zoo")
+		must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+		must(t, store.AddVariable(pets.ID, LocalVariable{Key: "kind", Value: "cat"}))
+		must(t, store.SetCollectionDescription(pets.ID, "All the pets"))
+		must(t, store.SetCookies(pets.ID, []Cookie{
+			{Name: "session", Value: "pets", Domain: "example.com", Path: "/"},
+			{Name: "pets-only", Value: "1", Domain: "example.com", Path: "/"},
+		}))
+		must(t, store.SetCookies(zoo.ID, []Cookie{{Name: "session", Value: "zoo", Domain: "example.com", Path: "/"}}))
+
+		must(t, store.MoveCollection(pets.ID, zoo.ID))
+		storage = mustLoad(t, store)
+		if len(storage.Collections) != 1 {
+			t.Fatalf("collections after move = %+v", storage.Collections)
+		}
+		zoo = storage.Collections[0]
+		if len(zoo.Folders) != 1 {
+			t.Fatalf("zoo folders = %+v", zoo.Folders)
+		}
+		folder := zoo.Folders[0]
+		if folder.Name != "Pets" || len(folder.Requests) != 1 || folder.Requests[0].Url != "/pets" ||
+			len(folder.LocalVariables) != 1 || folder.LocalVariables[0].Value != "cat" || folder.Description != "All the pets" {
+			t.Errorf("moved folder = %+v", folder)
+		}
+		if len(zoo.Cookies) != 2 || zoo.Cookies[0].Value != "zoo" || zoo.Cookies[1].Name != "pets-only" {
+			t.Errorf("zoo cookies = %+v", zoo.Cookies)
+		}
+	})
+}
+
+func TestStoreCopyCollectionKeepsDescription(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		must(t, store.AddCollection("Zoo"))
+		storage := mustLoad(t, store)
+		pets, zoo := collectionNamed(t, storage, "Pets"), collectionNamed(t, storage, "Zoo")
+		must(t, store.SetCollectionDescription(pets.ID, "All the pets"))
+		must(t, store.SetCookies(pets.ID, []Cookie{{Name: "session", Value: "pets", Domain: "example.com", Path: "/"}}))
+
+		must(t, store.CopyCollection(pets.ID, zoo.ID))
+		storage = mustLoad(t, store)
+		zoo = collectionNamed(t, storage, "Zoo")
+		if len(zoo.Folders) != 1 || zoo.Folders[0].Description != "All the pets" || zoo.Folders[0].ID == pets.ID {
+			t.Errorf("copied folder = %+v", zoo.Folders)
+		}
+		if len(zoo.Cookies) != 1 || zoo.Cookies[0].Value != "pets" {
+			t.Errorf("zoo cookies = %+v", zoo.Cookies)
+		}
+		if pets = collectionNamed(t, storage, "Pets"); pets.Description != "All the pets" || len(pets.Cookies) != 1 {
+			t.Errorf("source changed by the copy: %+v", pets)
+		}
+	})
+}
+
+func TestStoreNestRefusesOwnSettings(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		must(t, store.AddCollection("Zoo"))
+		storage := mustLoad(t, store)
+		pets, zoo := collectionNamed(t, storage, "Pets"), collectionNamed(t, storage, "Zoo")
+		must(t, store.SetCollectionSettings(pets.ID, CollectionSettings{Retry: RetryPolicy{MaxAttempts: 3}}))
+
+		if err := store.MoveCollection(pets.ID, zoo.ID); err == nil {
+			t.Error("moved a collection with its own settings")
+		}
+		if err := store.CopyCollection(pets.ID, zoo.ID); err == nil {
+			t.Error("copied a collection with its own settings")
+		}
+		storage = mustLoad(t, store)
+		if len(storage.Collections) != 2 || len(collectionNamed(t, storage, "Zoo").Folders) != 0 {
+			t.Errorf("storage changed: %+v", storage.Collections)
+		}
+
+		must(t, store.SetCollectionSettings(zoo.ID, CollectionSettings{Retry: RetryPolicy{MaxAttempts: 3}}))
+		must(t, store.MoveCollection(pets.ID, zoo.ID))
+	})
+}
+
+func TestStoreRestoreCollections(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		must(t, store.AddCollection("Pets"))
+		must(t, store.AddCollection("Users"))
+		pets := collectionNamed(t, mustLoad(t, store), "Pets")
+		must(t, store.AddFolder(pets.ID, "", "admin"))
+		must(t, store.AddRequest(pets.ID, "", Api{Method: "GET", Url: "/pets"}))
+		snapshot := mustLoad(t, store)
+		pets = snapshot.Collections[0]
+		api := pets.Requests[0]
+
+		must(t, store.DeleteCollection(pets.ID))
+		if len(mustLoad(t, store).Collections) != 1 {
+			t.Fatal("collection not deleted")
+		}
+
+		// A deleted collection comes back where the order puts it.
+		must(t, store.RestoreCollections([]Collection{pets}, []string{pets.ID, snapshot.Collections[1].ID}))
+		restored := mustLoad(t, store)
+		if len(restored.Collections) != 2 || restored.Collections[0].ID != pets.ID {
+			t.Fatalf("restored = %+v", restored.Collections)
+		}
+		if requests := restored.Collections[0].Requests; len(requests) != 1 || requests[0].ID != api.ID || requests[0].Url != "/pets" {
+			t.Errorf("restored requests = %+v", requests)
+		}
+		must(t, store.RenameCollection(pets.ID, "Still editable"))
+
+		// An existing one is rewritten in place and keeps its cookies.
+		jar := []Cookie{{Name: "session", Value: "1", Domain: "example.com", Path: "/"}}
+		must(t, store.SetCookies(pets.ID, jar))
+		must(t, store.DeleteRequest(api.ID))
+		must(t, store.AddRequest(pets.ID, "", Api{Method: "POST", Url: "/extra"}))
+		must(t, store.RestoreCollections([]Collection{pets}, nil))
+		restored = mustLoad(t, store)
+		got := collectionNamed(t, restored, "Pets")
+		if len(got.Requests) != 1 || got.Requests[0].ID != api.ID || len(got.Folders) != 1 || len(got.Cookies) != 1 {
+			t.Errorf("restored in place = %+v", got)
+		}
+
+		// An order without a collection deletes it.
+		must(t, store.RestoreCollections(nil, []string{pets.ID}))
+		if n := len(mustLoad(t, store).Collections); n != 1 {
+			t.Errorf("%d collections left, want 1", n)
+		}
+	})
+}
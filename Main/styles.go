@@ -93,11 +93,14 @@ func statusLine(termWidth int) lipgloss.Style {
 var HelpTextStyle = lipgloss.NewStyle().
 	Align(lipgloss.Center)
 
-var bodyElementStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("10"))
-
-var bodyElementStyle2 = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("210"))
+var (
+	jsonKeyStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	jsonStringStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("210"))
+	jsonNumberStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("117"))
+	jsonBoolStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("213"))
+	jsonNullStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+	jsonPunctuationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+)
 
 func loadingStyle(termWidth int, termHeight int) lipgloss.Style {
 	return lipgloss.NewStyle().
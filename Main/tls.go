@@ -0,0 +1,174 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSSettings configure how a collection's requests verify servers and
+// authenticate to them. File paths may start with ~/.
+type TLSSettings struct {
+	CAFile             string `json:"caFile,omitempty"`
+	CertFile           string `json:"certFile,omitempty"`
+	KeyFile            string `json:"keyFile,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	MinVersion         string `json:"minVersion,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+}
+
+var tlsVersions = []struct {
+	Name    string
+	Version uint16
+}{
+	{"1.0", tls.VersionTLS10},
+	{"1.1", tls.VersionTLS11},
+	{"1.2", tls.VersionTLS12},
+	{"1.3", tls.VersionTLS13},
+}
+
+func parseTLSVersion(name string) (uint16, error) {
+	if name == "" {
+		return 0, nil
+	}
+	name = strings.TrimPrefix(strings.ToLower(name), "tls")
+	for _, v := range tlsVersions {
+		if v.Name == strings.TrimSpace(name) {
+			return v.Version, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown TLS version %q, use 1.0, 1.1, 1.2 or 1.3", name)
+}
+
+func tlsVersionName(version uint16) string {
+	for _, v := range tlsVersions {
+		if v.Version == version {
+			return "TLS " + v.Name
+		}
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+func (s TLSSettings) minVersionName() string {
+	if s.MinVersion == "" {
+		return "default"
+	}
+	return s.MinVersion
+}
+
+// tlsConfig builds the client TLS configuration, or nil when the settings
+// are all defaults.
+func tlsConfig(settings TLSSettings) (*tls.Config, error) {
+	if settings == (TLSSettings{}) {
+		return nil, nil
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+		ServerName:         settings.ServerName,
+	}
+
+	version, err := parseTLSVersion(settings.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	config.MinVersion = version
+
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(expandHome(settings.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", settings.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if settings.CertFile != "" || settings.KeyFile != "" {
+		keyFile := settings.KeyFile
+		if keyFile == "" {
+			keyFile = settings.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(expandHome(settings.CertFile), expandHome(keyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// TLSInfo is what was negotiated with the server, for the response page.
+type TLSInfo struct {
+	Version      string
+	CipherSuite  string
+	ServerName   string
+	Certificates []CertificateInfo
+}
+
+type CertificateInfo struct {
+	Subject     string
+	Issuer      string
+	DNSNames    []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Fingerprint string
+}
+
+func newTLSInfo(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+	info := &TLSInfo{
+		Version:     tlsVersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	for _, cert := range state.PeerCertificates {
+		sum := sha256.Sum256(cert.Raw)
+		info.Certificates = append(info.Certificates, CertificateInfo{
+			Subject:     cert.Subject.String(),
+			Issuer:      cert.Issuer.String(),
+			DNSNames:    cert.DNSNames,
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			Fingerprint: hex.EncodeToString(sum[:]),
+		})
+	}
+	return info
+}
+
+// describe lists the connection and the server's certificate chain, leaf
+// first.
+func (info *TLSInfo) describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TLS: %s, %s", info.Version, info.CipherSuite)
+	if info.ServerName != "" {
+		fmt.Fprintf(&b, ", SNI %s", info.ServerName)
+	}
+	b.WriteString("\n")
+	now := time.Now()
+	for i, cert := range info.Certificates {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, cert.Subject)
+		fmt.Fprintf(&b, "     Issuer: %s\n", cert.Issuer)
+		if len(cert.DNSNames) > 0 {
+			fmt.Fprintf(&b, "     DNS: %s\n", strings.Join(cert.DNSNames, ", "))
+		}
+		validity := fmt.Sprintf("     Valid: %s to %s", cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"))
+		if now.After(cert.NotAfter) {
+			validity = StatusErrorStyle.Render(validity + " (expired)")
+		}
+		b.WriteString(validity + "\n")
+		fmt.Fprintf(&b, "     SHA-256: %s\n", cert.Fingerprint)
+	}
+	return b.String()
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+const undoLimit = 100
+
+// change is one edit of the workspace, kept as the store calls that take it
+// back and make it again rather than as copies of the whole workspace.
+type change struct {
+	undo  func(store Store) error
+	redo  func(store Store) error
+	scope editScope
+}
+
+// editScope is what to reload after a change is undone or redone: some
+// collections, or the whole workspace when the collection list or the
+// global settings changed.
+type editScope struct {
+	collections []string
+	workspace   bool
+}
+
+// record adds an edit to the undo history. Any new edit discards the redo
+// history.
+func (m *model) record(c change) {
+	m.undoStack = append(m.undoStack, c)
+	if len(m.undoStack) > undoLimit {
+		m.undoStack = m.undoStack[len(m.undoStack)-undoLimit:]
+	}
+	m.redoStack = nil
+}
+
+func (m *model) undo() {
+	if len(m.undoStack) == 0 {
+		return
+	}
+	c := m.undoStack[len(m.undoStack)-1]
+	if err := c.undo(m.store); err != nil {
+		m.errorMessage = "Failed to undo: " + err.Error()
+		m.hasError = true
+		return
+	}
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.redoStack = append(m.redoStack, c)
+	m.reload(c.scope)
+	m.afterRestore()
+}
+
+func (m *model) redo() {
+	if len(m.redoStack) == 0 {
+		return
+	}
+	c := m.redoStack[len(m.redoStack)-1]
+	if err := c.redo(m.store); err != nil {
+		m.errorMessage = "Failed to redo: " + err.Error()
+		m.hasError = true
+		return
+	}
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	m.undoStack = append(m.undoStack, c)
+	m.reload(c.scope)
+	m.afterRestore()
+}
+
+func (m *model) reload(scope editScope) bool {
+	if scope.workspace {
+		return m.refreshStorage()
+	}
+	return m.refreshCollections(scope.collections...)
+}
+
+// externalChange shows a workspace that was saved outside the app. The undo
+// history holds edits made before it, and replaying them would overwrite
+// what changed, so it is dropped. The watcher also reports our own saves;
+// those match what is shown and are ignored.
+func (m *model) externalChange(storage Storage) {
+	if reflect.DeepEqual(storage, m.storage) {
+		return
+	}
+	m.undoStack = nil
+	m.redoStack = nil
+	m.applyStorage(storage)
+}
+
+// savedRequest reloads a request of the selected collection after an edit
+// of it and records the edit, which undo reverts by writing back the
+// request as it was.
+func (m *model) savedRequest(apiID string) {
+	previous := findApi(&m.storage, apiID)
+	scope := editScope{collections: []string{m.SelectedCollection.ID}}
+	if !m.reload(scope) || previous == nil {
+		return
+	}
+	before := *previous
+	current := findApi(&m.storage, apiID)
+	if current == nil {
+		return
+	}
+	after := *current
+	m.record(change{
+		undo:  func(store Store) error { return store.UpdateRequest(before) },
+		redo:  func(store Store) error { return store.UpdateRequest(after) },
+		scope: scope,
+	})
+}
+
+// savedCollection follows an edit inside the selected collection.
+func (m *model) savedCollection() {
+	m.savedCollections(m.SelectedCollection.ID)
+}
+
+// savedCollections reloads the collections an edit changed, such as a
+// request moved from one to another, and records the edit, which undo
+// reverts by restoring those collections.
+func (m *model) savedCollections(ids ...string) {
+	var touched []string
+	for _, id := range ids {
+		if id != "" && !slices.Contains(touched, id) {
+			touched = append(touched, id)
+		}
+	}
+	before := m.collectionsByID(touched)
+	scope := editScope{collections: touched}
+	if !m.reload(scope) {
+		return
+	}
+	after := m.collectionsByID(touched)
+	m.record(change{
+		undo:  func(store Store) error { return store.RestoreCollections(before, nil) },
+		redo:  func(store Store) error { return store.RestoreCollections(after, nil) },
+		scope: scope,
+	})
+}
+
+func (m *model) collectionsByID(ids []string) []Collection {
+	var collections []Collection
+	for _, id := range ids {
+		if i := indexByID(m.storage.Collections, id); i >= 0 {
+			collections = append(collections, m.storage.Collections[i])
+		}
+	}
+	return collections
+}
+
+// savedWorkspace reloads the workspace after an edit of the collection list
+// and records the edit. touched names the collections whose contents it
+// changed as well.
+func (m *model) savedWorkspace(touched ...string) {
+	before := m.storage
+	scope := editScope{workspace: true}
+	if !m.reload(scope) {
+		return
+	}
+	after := m.storage
+	m.record(change{
+		undo:  restoreWorkspace(before, after, touched),
+		redo:  restoreWorkspace(after, before, touched),
+		scope: scope,
+	})
+}
+
+// restoreWorkspace returns the store calls that take the collections from
+// how they are in current back to how they are in target.
+func restoreWorkspace(target Storage, current Storage, touched []string) func(Store) error {
+	order := make([]string, 0, len(target.Collections))
+	var collections []Collection
+	for _, collection := range target.Collections {
+		order = append(order, collection.ID)
+		if slices.Contains(touched, collection.ID) || indexByID(current.Collections, collection.ID) < 0 {
+			collections = append(collections, collection)
+		}
+	}
+	return func(store Store) error {
+		return store.RestoreCollections(collections, order)
+	}
+}
+
+// savedSettings reloads the workspace after an edit of the global settings
+// and records the edit.
+func (m *model) savedSettings() {
+	before := m.storage.Settings
+	scope := editScope{workspace: true}
+	if !m.reload(scope) {
+		return
+	}
+	after := m.storage.Settings
+	m.record(change{
+		undo:  func(store Store) error { return store.SetGlobalSettings(before) },
+		redo:  func(store Store) error { return store.SetGlobalSettings(after) },
+		scope: scope,
+	})
+}
+
+// afterRestore leaves pages whose collection, folder or request no longer
+// exists and keeps the cursor inside the list it is on.
+func (m *model) afterRestore() {
+	m.editing = false
+	global := m.CurrentPage == SettingsPage && m.settingsScope == globalScope || m.CurrentPage == HistoryPage
+	if m.CurrentPage != HomePage && m.CurrentPage != FinderPage && !global &&
+		indexByID(m.Collections, m.SelectedCollection.ID) < 0 {
+		m.CurrentPage = HomePage
+	}
+	if m.CurrentPage != HomePage && m.CurrentPage != FinderPage && !global {
+		apiGone := indexByID(m.Apis, m.SelectedApi.ID) < 0
+		switch {
+		case m.SelectedFolder.ID != "" && findFolder(m.SelectedCollection.Folders, m.SelectedFolder.ID) == nil:
+			m.backToCollection()
+		case m.CurrentPage == ApiPage, m.CurrentPage == RequestPage,
+			m.CurrentPage == QueryParamsPage, m.CurrentPage == ResponsePage,
+			m.CurrentPage == PathParamsPage:
+			if apiGone {
+				m.backToCollection()
+			}
+		case m.CurrentPage == SettingsPage:
+			if m.settingsScope == requestScope && apiGone {
+				m.backToCollection()
+			}
+		case m.CurrentPage == HeadersPage:
+			if m.SelectedFolder.ID == "" && apiGone {
+				m.backToCollection()
+			}
+		case m.CurrentPage == DocsPage:
+			if m.docsApiID != "" && apiGone {
+				m.backToCollection()
+			}
+		}
+	}
+
+	length := 0
+	switch m.CurrentPage {
+	case HomePage:
+		length = len(m.Collections)
+	case CollectionPage:
+		length = len(collectionRows(&m.SelectedCollection, m.expanded))
+	case RequestPage:
+		length = len(m.BodyFields)
+	case HeadersPage:
+		length = len(m.Headers)
+	case QueryParamsPage:
+		length = len(m.QueryParams)
+	case VariablesPage:
+		length = len(m.LocalVariables)
+	case PathParamsPage:
+		length = len(m.PathParams)
+	case CookiesPage:
+		length = len(m.SelectedCollection.Cookies)
+	case SettingsPage:
+		length = len(m.settingsForm().Rows)
+	default:
+		return
+	}
+	m.pointer = min(m.pointer, max(length-1, 0))
+}
+
+// inputFocused reports whether a text input or a delete prompt has the
+// keyboard. Undo and redo wait until it is closed so they never change what
+// is being edited or confirmed.
+func (m model) inputFocused() bool {
+	if m.editing || m.confirmDeleteID != "" || m.descriptionInput.Focused() {
+		return true
+	}
+	for _, input := range []textinput.Model{
+		m.NewApiInput, m.NewCollectionInput, m.editingApi, m.editingCollection,
+		m.editingCurrentApi, m.NewFolderInput, m.editingFolder, m.editingAuth, m.editingName,
+		m.finderInput, m.historyInput, m.addHeaderKey, m.addHeaderValue, m.editingHeader,
+		m.newBodyFieldInput, m.bodyFiledValueInput, m.editingBodyFields, m.addQueryParamsKey,
+		m.addQueryParamsValue, m.editingQueryParams, m.editingPathParam, m.editingCookie,
+		m.editingSetting, m.addVariableKey, m.addVariableValue, m.editingLocalVariables,
+		m.saveInput, m.treeVariableInput, m.filterInput, m.searchInput,
+	} {
+		if input.Focused() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(m model, keys ...tea.KeyMsg) model {
+	for _, key := range keys {
+		updated, _ := m.Update(key)
+		m = updated.(model)
+	}
+	return m
+}
+
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+var (
+	keyEnter = tea.KeyMsg{Type: tea.KeyEnter}
+	keyEsc   = tea.KeyMsg{Type: tea.KeyEsc}
+	keyUndo  = tea.KeyMsg{Type: tea.KeyCtrlZ}
+)
+
+func testModel(t *testing.T) model {
+	t.Helper()
+	store := NewMemoryStore(Storage{Version: storageVersion, Collections: []Collection{}})
+	return NewModel(store, mustLoad(t, store))
+}
+
+func TestUndoWaitsForFocusedInput(t *testing.T) {
+	m := testModel(t)
+	m = press(m, runes(":"), runes("Pets"), keyEnter)
+	if len(m.Collections) != 1 {
+		t.Fatalf("collections = %+v", m.Collections)
+	}
+
+	m = press(m, runes(":"), runes("Zo"), keyUndo)
+	if len(m.Collections) != 1 || !m.NewCollectionInput.Focused() {
+		t.Fatalf("undo ran while typing: %d collections, input focused %v", len(m.Collections), m.NewCollectionInput.Focused())
+	}
+
+	m = press(m, keyEsc, keyUndo)
+	if len(m.Collections) != 0 {
+		t.Errorf("undo after closing the input left %+v", m.Collections)
+	}
+}
+
+func TestFolderDeleteAsksFirst(t *testing.T) {
+	m := testModel(t)
+	m = press(m, runes(":"), runes("Pets"), keyEnter, keyEnter, runes("n"), runes("Admin"), keyEnter)
+	if m.CurrentPage != CollectionPage || len(m.SelectedCollection.Folders) != 1 {
+		t.Fatalf("page %v, folders %+v", m.CurrentPage, m.SelectedCollection.Folders)
+	}
+
+	m = press(m, runes("d"))
+	if len(m.SelectedCollection.Folders) != 1 || m.confirmDeleteID == "" {
+		t.Fatal("folder deleted without asking")
+	}
+	m = press(m, keyUndo)
+	if len(m.SelectedCollection.Folders) != 1 || m.confirmDeleteID != "" {
+		t.Fatalf("other keys should cancel the prompt: folders %d, prompt %q", len(m.SelectedCollection.Folders), m.confirmDeleteID)
+	}
+
+	m = press(m, runes("d"), runes("y"))
+	if len(m.SelectedCollection.Folders) != 0 {
+		t.Errorf("folder not deleted after confirming")
+	}
+}
+
+func TestUndoAndRedoOneEdit(t *testing.T) {
+	m := testModel(t)
+	m = press(m, runes(":"), runes("Pets"), keyEnter, keyEnter, runes("n"), runes("Admin"), keyEnter)
+	if len(m.SelectedCollection.Folders) != 1 {
+		t.Fatalf("folders = %+v", m.SelectedCollection.Folders)
+	}
+	folderID := m.SelectedCollection.Folders[0].ID
+
+	m = press(m, keyUndo)
+	if len(m.SelectedCollection.Folders) != 0 || len(m.Collections) != 1 {
+		t.Fatalf("after undo: collections %d, folders %+v", len(m.Collections), m.SelectedCollection.Folders)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlY})
+	if len(m.SelectedCollection.Folders) != 1 || m.SelectedCollection.Folders[0].ID != folderID {
+		t.Errorf("after redo: folders %+v, want %s back", m.SelectedCollection.Folders, folderID)
+	}
+}
+
+func TestExternalChangeDropsUndo(t *testing.T) {
+	m := testModel(t)
+	m = press(m, runes(":"), runes("Pets"), keyEnter)
+
+	// The watcher reports our own save; undo still works after it.
+	updated, _ := m.Update(fileChangedMsg(m.storage))
+	m = updated.(model)
+	if len(m.undoStack) != 1 {
+		t.Fatalf("own save dropped the undo history: %d entries", len(m.undoStack))
+	}
+
+	external := mustLoad(t, m.store)
+	external.Collections[0].Name = "Renamed elsewhere"
+	must(t, m.store.RestoreCollections(external.Collections, nil))
+	updated, _ = m.Update(fileChangedMsg(external))
+	m = updated.(model)
+
+	m = press(m, keyUndo)
+	if len(m.Collections) != 1 || m.Collections[0].Name != "Renamed elsewhere" {
+		t.Errorf("undo overwrote an external change: %+v", m.Collections)
+	}
+}
@@ -1,6 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -15,37 +21,71 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.hasError = true
 		return m, nil
 
+	case downloadProgressMsg:
+		if msg.events == m.requestEvents {
+			m.download = msg
+		}
+		return m, waitForRequest(msg.events)
+
 	case apiResponseMsg:
+		if msg.events != m.requestEvents {
+			if msg.response.BodyFile != "" {
+				os.Remove(msg.response.BodyFile)
+			}
+			return m, nil
+		}
+		m.cancelRequest = nil
+		m.requestEvents = nil
+		if m.CurrentPage != LoadingPage {
+			if msg.response.BodyFile != "" {
+				os.Remove(msg.response.BodyFile)
+			}
+			return m, nil
+		}
+		if m.apiResponse.BodyFile != "" {
+			os.Remove(m.apiResponse.BodyFile)
+		}
 		m.apiResponse = msg.response
+		m.Responses, _ = HandleJson(m.apiResponse)
 		m.CurrentPage = ApiPage
+		m.jsonTree = nil
+		m.filterOutput = nil
+		filterCmd := m.updateFilter()
 		if m.viewportReady {
-			m.apiViewport.SetContent(BuildApiPageContent(m, m.termWidth))
+			m.refreshApiContent()
 			m.apiViewport.GotoTop()
 		}
+		if msg.response.CookiesChanged {
+			if err := m.store.SetCookies(m.SelectedCollection.ID, msg.response.Cookies); err != nil {
+				return m, tea.Batch(filterCmd, showErrorCommand("Failed to save cookies: "+err.Error()))
+			}
+			m.refreshCollection()
+		}
+		if history, ok := m.store.(HistoryStore); ok {
+			entry := HistoryEntry{
+				SentAt:          time.Now(),
+				Method:          m.SelectedApi.Method,
+				Url:             displayURL(m.SelectedApi),
+				StatusCode:      msg.response.StatusCode,
+				Status:          msg.response.Status,
+				ResponseHeaders: msg.response.Headers,
+				ResponseBody:    msg.response.Body,
+			}
+			if err := history.RecordHistory(m.SelectedApi.ID, entry); err != nil {
+				return m, tea.Batch(filterCmd, showErrorCommand("Failed to record history: "+err.Error()))
+			}
+		}
+		return m, filterCmd
+
+	case filterResultMsg:
+		m.applyFilterResult(msg)
+		if m.viewportReady && m.CurrentPage == ApiPage {
+			m.refreshApiContent()
+		}
 		return m, nil
 
 	case fileChangedMsg:
-		m.storage = Storage(msg)
-		m.Collections = m.storage.Collections
-
-		if m.CurrentPage == CollectionPage || m.CurrentPage == HeadersPage ||
-			m.CurrentPage == RequestPage || m.CurrentPage == QueryParamsPage ||
-			m.CurrentPage == ResponsePage || m.CurrentPage == VariablesPage {
-
-			if m.collectionIndex >= 0 && m.collectionIndex < len(m.Collections) {
-				m.SelectedCollection = m.Collections[m.collectionIndex]
-				m.Apis = m.SelectedCollection.Requests
-				m.LocalVariables = m.SelectedCollection.LocalVariables
-
-				if m.ApiIndex >= 0 && m.ApiIndex < len(m.Apis) {
-					m.SelectedApi = m.Apis[m.ApiIndex]
-
-					m.Headers = m.SelectedApi.Headers
-					m.BodyFields = m.SelectedApi.BodyField
-					m.QueryParams = m.SelectedApi.QueryParams
-				}
-			}
-		}
+		m.externalChange(Storage(msg))
 
 	case tea.WindowSizeMsg:
 
@@ -63,39 +103,83 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Update viewport content if we're on ApiPage
 		if m.CurrentPage == ApiPage {
-			m.apiViewport.SetContent(BuildApiPageContent(m, m.termWidth))
+			m.refreshApiContent()
+		}
+		if m.CurrentPage == DocsPage {
+			m.refreshDocs()
 		}
 
 	case tea.KeyMsg:
-		switch m.CurrentPage {
-		case HomePage:
-			m, cmd := UpdateHomePage(m, msg)
-			return m, cmd
-		case CollectionPage:
-			m, cmd := UpdateCollectionPage(m, msg)
-			return m, cmd
-		case ApiPage:
-			m, cmd := UpdateApiPage(m, msg)
-			return m, cmd
-		case RequestPage:
-			m, cmd := UpdateReqPage(m, msg)
-			return m, cmd
-		case HeadersPage:
-			m, cmd := UpdateHeadersPage(m, msg)
-			return m, cmd
-		case QueryParamsPage:
-			m, cmd := UpdateQueryParamsPage(m, msg)
-			return m, cmd
-		case LoadingPage:
-			m, cmd := UpdateLoadingPage(m, msg)
-			return m, cmd
-		case ResponsePage:
-			m, cmd := UpdateResponsePage(m, msg)
-			return m, cmd
-		case VariablesPage:
-			m, cmd := UpdateVariablesPage(m, msg)
-			return m, cmd
+		switch msg.String() {
+		case "ctrl+z":
+			if !m.inputFocused() {
+				m.undo()
+				return m, nil
+			}
+		case "ctrl+y":
+			if !m.inputFocused() {
+				m.redo()
+				return m, nil
+			}
+		case "ctrl+p":
+			if m.CurrentPage != FinderPage && !m.descriptionInput.Focused() {
+				return m, m.openFinder()
+			}
 		}
+
+		return updatePage(m, msg)
+	}
+
+	return m, nil
+}
+
+func updatePage(m model, msg tea.KeyMsg) (model, tea.Cmd) {
+	switch m.CurrentPage {
+	case HomePage:
+		m, cmd := UpdateHomePage(m, msg)
+		return m, cmd
+	case CollectionPage:
+		m, cmd := UpdateCollectionPage(m, msg)
+		return m, cmd
+	case ApiPage:
+		m, cmd := UpdateApiPage(m, msg)
+		return m, cmd
+	case RequestPage:
+		m, cmd := UpdateReqPage(m, msg)
+		return m, cmd
+	case HeadersPage:
+		m, cmd := UpdateHeadersPage(m, msg)
+		return m, cmd
+	case QueryParamsPage:
+		m, cmd := UpdateQueryParamsPage(m, msg)
+		return m, cmd
+	case LoadingPage:
+		m, cmd := UpdateLoadingPage(m, msg)
+		return m, cmd
+	case ResponsePage:
+		m, cmd := UpdateResponsePage(m, msg)
+		return m, cmd
+	case VariablesPage:
+		m, cmd := UpdateVariablesPage(m, msg)
+		return m, cmd
+	case DocsPage:
+		m, cmd := UpdateDocsPage(m, msg)
+		return m, cmd
+	case PathParamsPage:
+		m, cmd := UpdatePathParamsPage(m, msg)
+		return m, cmd
+	case CookiesPage:
+		m, cmd := UpdateCookiesPage(m, msg)
+		return m, cmd
+	case SettingsPage:
+		m, cmd := UpdateSettingsPage(m, msg)
+		return m, cmd
+	case FinderPage:
+		m, cmd := UpdateFinderPage(m, msg)
+		return m, cmd
+	case HistoryPage:
+		m, cmd := UpdateHistoryPage(m, msg)
+		return m, cmd
 	}
 
 	return m, nil
@@ -107,6 +191,22 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 
+		if m.confirmDeleteID != "" {
+			id := m.confirmDeleteID
+			m.confirmDeleteID = ""
+			if msg.String() != "y" {
+				return m, nil
+			}
+			if err := m.store.DeleteCollection(id); err != nil {
+				return m, showErrorCommand("Failed to delete collection: " + err.Error())
+			}
+			m.savedWorkspace()
+			if m.pointer >= len(m.Collections) && m.pointer > 0 {
+				m.pointer--
+			}
+			return m, nil
+		}
+
 		if m.editing {
 			switch msg.String() {
 
@@ -114,9 +214,10 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.editingCollection.Blur()
 				m.editing = false
 			case "enter":
-				if err := editCollection(m.storage, m.SelectedCollection, m.editingCollection.Value()); err != nil {
+				if err := m.store.RenameCollection(m.SelectedCollection.ID, m.editingCollection.Value()); err != nil {
 					return m, showErrorCommand("Failed to edit Collection: " + err.Error())
 				}
+				m.savedCollection()
 				m.editingApi.Blur()
 				m.editing = false
 			}
@@ -132,9 +233,10 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 				return m, nil
 			case "enter":
 
-				if err := AddCollection(m.storage, m.Collections, m.NewCollectionInput.Value()); err != nil {
+				if err := m.store.AddCollection(m.NewCollectionInput.Value()); err != nil {
 					return m, showErrorCommand("Failed to add collection: " + err.Error())
 				}
+				m.savedWorkspace()
 				m.NewCollectionInput.SetValue("")
 				m.NewCollectionInput.Blur()
 
@@ -146,6 +248,10 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 		switch msg.String() {
 
 		case "esc":
+			m.cancelPendingRequest()
+			if m.apiResponse.BodyFile != "" {
+				os.Remove(m.apiResponse.BodyFile)
+			}
 			return m, tea.Quit
 
 		case "up", "k":
@@ -159,7 +265,7 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 		case "enter":
 			m.CurrentPage = CollectionPage
 			m.SelectedCollection = m.storage.Collections[m.pointer]
-			m.Apis = m.SelectedCollection.Requests
+			m.Apis = allApis(m.SelectedCollection)
 			m.collectionIndex = m.pointer
 			m.pointer = 0
 
@@ -167,17 +273,84 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 			m.NewCollectionInput.Focus()
 			return m, nil
 
-		case "d":
+		case "S":
+			m.CurrentPage = SettingsPage
+			m.settingsScope = globalScope
+			m.pointer = 0
+
+		case "H":
+			return m, m.openHistory()
+
+		case "y":
 			if len(m.Collections) > 0 {
-				selectedCollection := m.storage.Collections[m.pointer]
-				newCollections, err := deleteCollection(selectedCollection, m.storage)
-				if err != nil {
-					return m, showErrorCommand("Failed to delete collection: " + err.Error())
+				if err := m.store.DuplicateCollection(m.Collections[m.pointer].ID); err != nil {
+					return m, showErrorCommand("Failed to duplicate collection: " + err.Error())
 				}
-				m.Collections = newCollections
-				if m.pointer >= len(m.Collections) && m.pointer > 0 {
-					m.pointer--
+				m.savedWorkspace()
+				m.pointer++
+			}
+
+		case "J", "K":
+			if len(m.Collections) > 0 {
+				delta := 1
+				if msg.String() == "K" {
+					delta = -1
+				}
+				if err := m.store.ReorderCollection(m.Collections[m.pointer].ID, delta); err != nil {
+					return m, showErrorCommand("Failed to reorder collection: " + err.Error())
+				}
+				m.savedWorkspace()
+				m.pointer = min(max(m.pointer+delta, 0), len(m.Collections)-1)
+			}
+
+		case "m", "c":
+			if len(m.Collections) > 0 {
+				id := m.Collections[m.pointer].ID
+				copying := msg.String() == "c"
+				if m.clipboardCollectionID == id && m.clipboardCopy == copying {
+					m.clipboardCollectionID = ""
+				} else {
+					m.clipboardApiID = ""
+					m.clipboardCollectionID = id
+					m.clipboardCopy = copying
+				}
+			}
+
+		case "p":
+			if len(m.Collections) == 0 {
+				return m, nil
+			}
+			target := m.Collections[m.pointer].ID
+			if m.clipboardApiID != "" {
+				if _, err := m.pasteApi(target, ""); err != nil {
+					return m, showErrorCommand("Failed to paste api: " + err.Error())
 				}
+			} else if m.clipboardCollectionID != "" {
+				var err error
+				if m.clipboardCopy {
+					err = m.store.CopyCollection(m.clipboardCollectionID, target)
+				} else {
+					err = m.store.MoveCollection(m.clipboardCollectionID, target)
+				}
+				if err != nil {
+					return m, showErrorCommand("Failed to paste collection: " + err.Error())
+				}
+				m.clipboardCollectionID = ""
+				m.savedWorkspace(target)
+				m.pointer = max(indexByID(m.Collections, target), 0)
+			}
+
+		case "i":
+			if len(m.Collections) > 0 {
+				m.SelectedCollection = m.Collections[m.pointer]
+				m.Apis = allApis(m.SelectedCollection)
+				m.collectionIndex = m.pointer
+				m.openDocs("")
+			}
+
+		case "d":
+			if len(m.Collections) > 0 {
+				m.confirmDeleteID = m.Collections[m.pointer].ID
 			}
 
 		case "e":
@@ -201,13 +374,68 @@ func UpdateHomePage(m model, msg tea.Msg) (model, tea.Cmd) {
 
 func UpdateCollectionPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	var cmd tea.Cmd
+	rows := collectionRows(&m.SelectedCollection, m.expanded)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.confirmDeleteID != "" {
+			id := m.confirmDeleteID
+			m.confirmDeleteID = ""
+			if msg.String() != "y" {
+				return m, nil
+			}
+			if err := m.store.DeleteFolder(id); err != nil {
+				return m, showErrorCommand("Failed to delete folder: " + err.Error())
+			}
+			m.savedCollection()
+			if m.pointer >= len(collectionRows(&m.SelectedCollection, m.expanded)) && m.pointer > 0 {
+				m.pointer--
+			}
+			return m, nil
+		}
+
 		if m.editing {
+			if m.editingName.Focused() {
+				switch msg.String() {
+				case "enter":
+					api := *rows[m.pointer].Api
+					api.Name = m.editingName.Value()
+					if err := m.store.UpdateRequest(api); err != nil {
+						return m, showErrorCommand("Failed to rename api: " + err.Error())
+					}
+					m.savedRequest(api.ID)
+					m.editingName.Blur()
+					m.editing = false
+				case "esc":
+					m.editingName.Blur()
+					m.editing = false
+				}
+
+				m.editingName, cmd = m.editingName.Update(msg)
+				return m, cmd
+			}
+
+			if m.editingFolder.Focused() {
+				switch msg.String() {
+				case "enter":
+					if err := m.store.RenameFolder(rows[m.pointer].Folder.ID, m.editingFolder.Value()); err != nil {
+						return m, showErrorCommand("Failed to rename folder: " + err.Error())
+					}
+					m.savedCollection()
+					m.editingFolder.Blur()
+					m.editing = false
+				case "esc":
+					m.editingFolder.Blur()
+					m.editing = false
+				}
+
+				m.editingFolder, cmd = m.editingFolder.Update(msg)
+				return m, cmd
+			}
+
 			switch msg.String() {
 			case "enter":
-				if err := editApi(m.storage, m.collectionIndex, m.SelectedApi, m.editingApi.Value()); err != nil {
+				if err := m.updateApiLine(rows[m.pointer].Api.ID, m.editingApi.Value()); err != nil {
 					return m, showErrorCommand("Failed to edit api: " + err.Error())
 				}
 				m.editingApi.Blur()
@@ -221,16 +449,68 @@ func UpdateCollectionPage(m model, msg tea.Msg) (model, tea.Cmd) {
 			return m, cmd
 		}
 
+		if m.editingAuth.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.editingAuth.Blur()
+				return m, nil
+			case "enter":
+				auth, err := parseAuthInput(m.editingAuth.Value())
+				if err == nil {
+					err = m.store.SetFolderAuth(rows[m.pointer].Folder.ID, auth)
+				}
+				if err != nil {
+					return m, showErrorCommand("Failed to set folder auth: " + err.Error())
+				}
+				m.savedCollection()
+				m.editingAuth.Blur()
+				return m, nil
+			}
+
+			m.editingAuth, cmd = m.editingAuth.Update(msg)
+			return m, cmd
+		}
+
+		if m.NewFolderInput.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.NewFolderInput.Blur()
+				return m, nil
+			case "enter":
+				parentID := targetFolderID(rows, m.pointer)
+				if err := m.store.AddFolder(m.SelectedCollection.ID, parentID, m.NewFolderInput.Value()); err != nil {
+					return m, showErrorCommand("Failed to add folder: " + err.Error())
+				}
+				if parentID != "" {
+					m.expanded[parentID] = true
+				}
+				m.savedCollection()
+				m.NewFolderInput.SetValue("")
+				m.NewFolderInput.Blur()
+			}
+
+			m.NewFolderInput, cmd = m.NewFolderInput.Update(msg)
+			return m, cmd
+		}
+
 		if m.NewApiInput.Focused() {
 			switch msg.String() {
 			case "esc":
 				m.NewApiInput.Blur()
 				return m, nil
 			case "enter":
-
-				if err := AddApi(m.storage, m.collectionIndex, m.Apis, m.NewApiInput.Value()); err != nil {
+				folderID := targetFolderID(rows, m.pointer)
+				method, url, err := parseApiInput(m.NewApiInput.Value())
+				if err == nil {
+					err = m.store.AddRequest(m.SelectedCollection.ID, folderID, withURL(Api{Method: method}, url))
+				}
+				if err != nil {
 					return m, showErrorCommand("Failed to add api: " + err.Error())
 				}
+				if folderID != "" {
+					m.expanded[folderID] = true
+				}
+				m.savedCollection()
 				m.NewApiInput.SetValue("")
 				m.NewApiInput.Blur()
 			}
@@ -239,78 +519,217 @@ func UpdateCollectionPage(m model, msg tea.Msg) (model, tea.Cmd) {
 			return m, cmd
 		}
 
+		var row treeRow
+		if m.pointer < len(rows) {
+			row = rows[m.pointer]
+		}
+
 		switch msg.String() {
 		case "up", "k":
 			if m.pointer > 0 {
 				m.pointer--
 			}
 		case "down", "j":
-			if m.pointer < len(m.Apis)-1 {
+			if m.pointer < len(rows)-1 {
 				m.pointer++
 			}
+		case "right", "l":
+			if row.Folder != nil {
+				m.expanded[row.Folder.ID] = true
+			}
+		case "left":
+			if row.Folder != nil && m.expanded[row.Folder.ID] {
+				m.expanded[row.Folder.ID] = false
+			} else if row.ParentID != "" {
+				m.pointer = rowIndex(rows, row.ParentID)
+			}
 		case "enter":
-			m.SelectedApi = m.Apis[m.pointer]
+			if row.Folder != nil {
+				m.expanded[row.Folder.ID] = !m.expanded[row.Folder.ID]
+				return m, nil
+			}
+			if row.Api == nil {
+				return m, nil
+			}
+			m.SelectedApi = *row.Api
 
-			processedApi := processRequest(m.SelectedApi, m.SelectedCollection.LocalVariables)
+			_, variables := resolveRequest(m.SelectedCollection, m.SelectedApi)
+			processedApi := processRequest(m.SelectedApi, variables)
 
 			switch processedApi.Method {
 			case "POST", "DELETE", "PUT", "PATCH":
 				m.SelectedApi = processedApi
 				m.BodyFields = processedApi.BodyField
-				m.ApiIndex = m.pointer
 				m.CurrentPage = RequestPage
 				m.pointer = 0
 
 			case "GET":
-				m.CurrentPage = LoadingPage
-				m.ApiIndex = m.pointer
-				m.apiResponse = FetchData(m.SelectedApi, m)
-				m.Responses, _ = HandleJson(m.apiResponse)
-				return m, fetchApiCommand(m.SelectedApi, m)
+				return m, m.fetchApiCommand(m.SelectedApi)
 			}
 
 		case ":":
 			m.NewApiInput.Focus()
 			return m, nil
 
+		case "n":
+			m.NewFolderInput.Focus()
+			return m, nil
+
 		case "d":
-			if len(m.Apis) > 0 {
-				selectedApi := m.Apis[m.pointer]
-				newApis, err := deleteApi(selectedApi, m.storage, m.collectionIndex)
+			if row.Folder != nil {
+				m.confirmDeleteID = row.Folder.ID
+				return m, nil
+			}
+			if row.Api == nil {
+				return m, nil
+			}
+			if err := m.store.DeleteRequest(row.Api.ID); err != nil {
+				return m, showErrorCommand("Failed to delete api: " + err.Error())
+			}
+			m.savedCollection()
+			if m.pointer >= len(collectionRows(&m.SelectedCollection, m.expanded)) && m.pointer > 0 {
+				m.pointer--
+			}
+
+		case "e":
+			if row.Folder != nil {
+				m.editing = true
+				m.editingFolder = textinput.New()
+				m.editingFolder.SetValue(row.Folder.Name)
+				m.editingFolder.Focus()
+			} else if row.Api != nil {
+				m.editing = true
+				m.editingApi = textinput.New()
+				m.SelectedApi = *row.Api
+				m.editingApi.SetValue(m.SelectedApi.Method + " " + displayURL(m.SelectedApi))
+				m.editingApi.Focus()
+			}
+
+		case "r":
+			if row.Api != nil {
+				m.editing = true
+				m.editingName = textinput.New()
+				m.editingName.Placeholder = "Request name..."
+				m.editingName.SetValue(row.Api.Name)
+				m.editingName.Focus()
+			}
+
+		case "i":
+			if row.Api != nil {
+				m.SelectedApi = *row.Api
+				m.openDocs(row.Api.ID)
+			} else {
+				m.openDocs("")
+			}
+
+		case "a":
+			if row.Folder != nil {
+				m.editingAuth.SetValue(formatAuth(row.Folder.Auth))
+				m.editingAuth.Focus()
+				return m, nil
+			}
+
+		case "m", "c":
+			if row.Api != nil {
+				m.markApi(row.Api.ID, msg.String() == "c")
+			}
+
+		case "p":
+			if m.clipboardApiID != "" {
+				folderID := targetFolderID(rows, m.pointer)
+				pastedID, err := m.pasteApi(m.SelectedCollection.ID, folderID)
 				if err != nil {
-					return m, showErrorCommand("Failed to delete api: " + err.Error())
+					return m, showErrorCommand("Failed to paste api: " + err.Error())
 				}
-				m.Apis = newApis
-				if m.pointer >= len(m.Apis) && m.pointer > 0 {
-					m.pointer--
+				if folderID != "" {
+					m.expanded[folderID] = true
 				}
+				m.pointer = max(rowIndex(collectionRows(&m.SelectedCollection, m.expanded), pastedID), 0)
 			}
 
-		case "e":
-			m.editing = true
-			m.editingApi = textinput.New()
-			m.SelectedApi = m.Apis[m.pointer]
-			m.editingApi.SetValue(m.SelectedApi.Method + " " + m.SelectedApi.Url)
-			m.editingApi.Focus()
+		case "y":
+			if row.Api != nil {
+				if err := m.store.DuplicateRequest(row.Api.ID); err != nil {
+					return m, showErrorCommand("Failed to duplicate api: " + err.Error())
+				}
+				m.savedCollection()
+				if m.pointer < len(collectionRows(&m.SelectedCollection, m.expanded))-1 {
+					m.pointer++
+				}
+			}
+
+		case "J", "K":
+			delta := 1
+			if msg.String() == "K" {
+				delta = -1
+			}
+			var id string
+			var err error
+			if row.Folder != nil {
+				id = row.Folder.ID
+				err = m.store.ReorderFolder(id, delta)
+			} else if row.Api != nil {
+				id = row.Api.ID
+				err = m.store.ReorderRequest(id, delta)
+			} else {
+				return m, nil
+			}
+			if err != nil {
+				return m, showErrorCommand("Failed to reorder: " + err.Error())
+			}
+			m.savedCollection()
+			m.pointer = max(rowIndex(collectionRows(&m.SelectedCollection, m.expanded), id), 0)
 
 		case "esc":
 			m.CurrentPage = HomePage
 			m.pointer = m.collectionIndex
 
 		case "h":
+			if row.Folder != nil {
+				m.SelectedFolder = *row.Folder
+				m.Headers = row.Folder.Headers
+			} else if row.Api != nil {
+				m.SelectedApi = *row.Api
+				m.Headers = m.SelectedApi.Headers
+			} else {
+				return m, nil
+			}
 			m.CurrentPage = HeadersPage
-			m.SelectedApi = m.Apis[m.pointer]
-			m.Headers = m.SelectedApi.Headers
-			m.ApiIndex = m.pointer
 			m.pointer = 0
 
 		case "q":
-			m.CurrentPage = QueryParamsPage
-			m.SelectedApi = m.Apis[m.pointer]
-			m.ApiIndex = m.pointer
-			m.QueryParams = m.SelectedApi.QueryParams
+			if row.Api != nil {
+				m.CurrentPage = QueryParamsPage
+				m.SelectedApi = *row.Api
+				m.QueryParams = m.SelectedApi.QueryParams
+				m.pointer = 0
+			}
+
+		case "o":
+			m.CurrentPage = CookiesPage
+			m.pointer = 0
+
+		case "s":
+			if row.Api != nil {
+				m.CurrentPage = SettingsPage
+				m.SelectedApi = *row.Api
+				m.settingsScope = requestScope
+				m.pointer = 0
+			}
+
+		case "S":
+			m.CurrentPage = SettingsPage
+			m.settingsScope = collectionScope
 			m.pointer = 0
 
+		case "u":
+			if row.Api != nil {
+				m.CurrentPage = PathParamsPage
+				m.SelectedApi = *row.Api
+				m.PathParams = m.SelectedApi.PathParams
+				m.pointer = 0
+			}
+
 		case "x":
 			if m.hasError {
 				m.hasError = false
@@ -319,7 +738,12 @@ func UpdateCollectionPage(m model, msg tea.Msg) (model, tea.Cmd) {
 			}
 		case "v":
 			m.CurrentPage = VariablesPage
-			m.LocalVariables = m.SelectedCollection.LocalVariables
+			if row.Folder != nil {
+				m.SelectedFolder = *row.Folder
+				m.LocalVariables = row.Folder.LocalVariables
+			} else {
+				m.LocalVariables = m.SelectedCollection.LocalVariables
+			}
 			m.pointer = 0
 		}
 	}
@@ -328,6 +752,73 @@ func UpdateCollectionPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	return m, cmd
 }
 
+// targetFolderID is the folder new or moved requests go into: the folder
+// under the cursor, or the folder containing the request under the cursor.
+func targetFolderID(rows []treeRow, pointer int) string {
+	if pointer < 0 || pointer >= len(rows) {
+		return ""
+	}
+	if rows[pointer].Folder != nil {
+		return rows[pointer].Folder.ID
+	}
+	return rows[pointer].ParentID
+}
+
+// markApi puts a request on the clipboard, or takes it off when it is
+// already marked the same way.
+func (m *model) markApi(apiID string, copying bool) {
+	if m.clipboardApiID == apiID && m.clipboardCopy == copying {
+		m.clipboardApiID = ""
+		return
+	}
+	m.clipboardCollectionID = ""
+	m.clipboardApiID = apiID
+	m.clipboardCopy = copying
+}
+
+// pasteApi moves or copies the request on the clipboard into a collection or
+// folder and returns the ID of the pasted request.
+func (m *model) pasteApi(collectionID string, folderID string) (string, error) {
+	apiID := m.clipboardApiID
+	if !m.clipboardCopy {
+		source := collectionOfApi(m.storage, apiID)
+		if err := m.store.MoveRequest(apiID, collectionID, folderID); err != nil {
+			return "", err
+		}
+		m.clipboardApiID = ""
+		m.savedCollections(source, collectionID)
+		return apiID, nil
+	}
+
+	if err := m.store.CopyRequest(apiID, collectionID, folderID); err != nil {
+		return "", err
+	}
+	m.savedCollections(collectionID)
+	storage := m.storage
+	list, err := requestList(&storage, collectionID, folderID)
+	if err != nil || len(*list) == 0 {
+		return "", err
+	}
+	return (*list)[len(*list)-1].ID, nil
+}
+
+// backToCollection returns to the collection tree with the cursor on the
+// request or folder that was being viewed.
+func (m *model) backToCollection() {
+	id := m.SelectedApi.ID
+	if m.SelectedFolder.ID != "" {
+		id = m.SelectedFolder.ID
+	}
+	m.jsonTree = nil
+	m.filterInput.SetValue("")
+	m.updateFilter()
+	m.searchInput.SetValue("")
+	m.searchMatches = nil
+	m.SelectedFolder = Folder{}
+	m.CurrentPage = CollectionPage
+	m.pointer = max(rowIndex(collectionRows(&m.SelectedCollection, m.expanded), id), 0)
+}
+
 func UpdateApiPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -341,28 +832,21 @@ func UpdateApiPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.editing = false
 				// Rebuild to hide the input
 				if m.viewportReady {
-					m.apiViewport.SetContent(BuildApiPageContent(m, m.termWidth))
+					m.refreshApiContent()
 				}
 				return m, nil
 
 			case "enter":
-				if err := editApi(m.storage, m.collectionIndex, m.SelectedApi, m.editingCurrentApi.Value()); err != nil {
+				if err := m.updateApiLine(m.SelectedApi.ID, m.editingCurrentApi.Value()); err != nil {
 					return m, showErrorCommand("Failed to edit api: " + err.Error())
 				}
 
-				// Update local state
-				m.storage, _ = ReadFile()
-				m.Collections = m.storage.Collections
-				m.SelectedCollection = m.Collections[m.collectionIndex]
-				m.Apis = m.SelectedCollection.Requests
-				m.SelectedApi = m.Apis[m.pointer]
-
 				m.editingCurrentApi.Blur()
 				m.editing = false
 
 				// Rebuild content ONLY here with the new API - this will re-fetch
 				if m.viewportReady {
-					m.apiViewport.SetContent(BuildApiPageContent(m, m.termWidth))
+					m.refreshApiContent()
 				}
 				return m, nil
 			}
@@ -371,16 +855,96 @@ func UpdateApiPage(m model, msg tea.Msg) (model, tea.Cmd) {
 
 			// Show typing but don't re-fetch API yet
 			if m.viewportReady {
-				m.apiViewport.SetContent(BuildApiPageContent(m, m.termWidth))
+				m.refreshApiContent()
+			}
+
+			return m, cmd
+		}
+
+		if m.filterInput.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.filterInput.SetValue("")
+				m.filterInput.Blur()
+			case "enter":
+				m.filterInput.Blur()
+			default:
+				m.filterInput, cmd = m.filterInput.Update(msg)
+			}
+			filterCmd := m.updateFilter()
+			if m.viewportReady {
+				m.refreshApiContent()
+			}
+			return m, tea.Batch(cmd, filterCmd)
+		}
+
+		if m.saveInput.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.saveInput.Blur()
+				return m, nil
+			case "enter":
+				if err := saveResponse(m.apiResponse, m.saveInput.Value(), m.saveHeaders); err != nil {
+					m.saveInput.Blur()
+					return m, showErrorCommand("Failed to save response: " + err.Error())
+				}
+				m.saveNotice = "Saved to " + m.saveInput.Value()
+				m.saveInput.Blur()
+				return m, nil
 			}
+			m.saveInput, cmd = m.saveInput.Update(msg)
+			return m, cmd
+		}
 
+		if m.searchInput.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.searchInput.SetValue("")
+				m.searchInput.Blur()
+			case "enter":
+				m.searchInput.Blur()
+			default:
+				m.searchInput, cmd = m.searchInput.Update(msg)
+			}
+			m.searchIndex = 0
+			m.refreshApiContent()
+			m.jumpToMatch(0)
 			return m, cmd
 		}
 
+		if m.jsonTree != nil {
+			return updateJSONTree(m, msg)
+		}
+
+		m.saveNotice = ""
 		switch msg.String() {
 		case "esc":
-			m.CurrentPage = CollectionPage
-			m.pointer = m.ApiIndex
+			if m.searchInput.Value() != "" {
+				m.searchInput.SetValue("")
+				m.refreshApiContent()
+				return m, nil
+			}
+			m.backToCollection()
+			return m, nil
+		case "/":
+			m.searchInput.Focus()
+			return m, textinput.Blink
+		case "s", "S":
+			m.saveHeaders = msg.String() == "S"
+			m.saveNotice = ""
+			m.saveInput = textinput.New()
+			m.saveInput.Prompt = "Save body to: "
+			if m.saveHeaders {
+				m.saveInput.Prompt = "Save headers and body to: "
+			}
+			m.saveInput.SetValue(suggestedFileName(m.apiResponse, m.SelectedApi.Url))
+			m.saveInput.Focus()
+			return m, textinput.Blink
+		case "n":
+			m.jumpToMatch(1)
+			return m, nil
+		case "N":
+			m.jumpToMatch(-1)
 			return m, nil
 		case "up", "k":
 			m.apiViewport.LineUp(1)
@@ -398,17 +962,27 @@ func UpdateApiPage(m model, msg tea.Msg) (model, tea.Cmd) {
 		case "e":
 			m.editing = true
 			m.editingCurrentApi = textinput.New()
-			m.editingCurrentApi.SetValue(m.SelectedApi.Method + " " + m.SelectedApi.Url)
+			m.editingCurrentApi.SetValue(m.SelectedApi.Method + " " + displayURL(m.SelectedApi))
 			m.editingCurrentApi.Focus()
 
 			// Rebuild viewport to show the editing input
 			if m.viewportReady {
-				m.apiViewport.SetContent(BuildApiPageContent(m, m.termWidth))
+				m.refreshApiContent()
 			}
 		case "r":
 			m.LocalVariables = m.SelectedCollection.LocalVariables
 			m.CurrentPage = ResponsePage
 			m.pointer = 0
+		case "|":
+			m.filterInput.Focus()
+			return m, textinput.Blink
+		case "t":
+			tree, err := parseJSONTree(m.responseBody())
+			if err != nil {
+				return m, showErrorCommand("Failed to open tree: " + err.Error())
+			}
+			m.jsonTree = tree
+			return m, nil
 		}
 	}
 
@@ -416,24 +990,104 @@ func UpdateApiPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	return m, cmd
 }
 
-func UpdateReqPage(m model, msg tea.Msg) (model, tea.Cmd) {
+func updateJSONTree(m model, msg tea.KeyMsg) (model, tea.Cmd) {
 	var cmd tea.Cmd
+	tree := m.jsonTree
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		if m.editingBodyFields.Focused() {
-			switch msg.String() {
-			case "esc":
-				m.editing = false
-				m.editingBodyFields.Blur()
-				return m, nil
-			case "enter":
-				m.BodyFields[m.pointer].Value = m.editingBodyFields.Value()
-				newBodyFields, err := addBodyField(m.storage, m.collectionIndex, m.ApiIndex, m.BodyFields)
-				if err != nil {
-					return m, showErrorCommand("Failed to edit body field: " + err.Error())
+	if m.treeVariableInput.Focused() {
+		switch msg.String() {
+		case "esc":
+			m.treeVariableInput.Blur()
+			return m, nil
+		case "enter":
+			variable := LocalVariable{
+				Key:   strings.TrimSpace(m.treeVariableInput.Value()),
+				Value: tree.current().text(),
+			}
+			if variable.Key == "" {
+				return m, showErrorCommand("Failed to save variable: name is empty")
+			}
+			if err := m.store.AddVariable(m.SelectedCollection.ID, variable); err != nil {
+				return m, showErrorCommand("Failed to save variable: " + err.Error())
+			}
+			m.savedCollection()
+			m.treeVariableInput.Blur()
+			return m, nil
+		}
+		m.treeVariableInput, cmd = m.treeVariableInput.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "t":
+		m.jsonTree = nil
+	case "up", "k":
+		tree.move(-1)
+	case "down", "j":
+		tree.move(1)
+	case "pgup", "b":
+		tree.move(-jsonTreeHeight(m))
+	case "pgdown", "f":
+		tree.move(jsonTreeHeight(m))
+	case "home", "g":
+		tree.Cursor = 0
+	case "end", "G":
+		tree.Cursor = len(tree.Rows) - 1
+	case "right", "l":
+		tree.expand()
+	case "left", "h":
+		tree.collapse()
+	case "enter", " ":
+		tree.toggle()
+	case "E":
+		tree.setExpanded(true)
+	case "C":
+		tree.setExpanded(false)
+	case "c":
+		if err := clipboard.WriteAll(tree.current().text()); err != nil {
+			return m, showErrorCommand("Failed to copy value: " + err.Error())
+		}
+	case "p":
+		if err := clipboard.WriteAll(tree.current().path()); err != nil {
+			return m, showErrorCommand("Failed to copy path: " + err.Error())
+		}
+	case "s":
+		m.treeVariableInput = textinput.New()
+		m.treeVariableInput.Placeholder = "Variable name..."
+		m.treeVariableInput.SetValue(tree.current().variableName())
+		m.treeVariableInput.Focus()
+	case "x":
+		if m.hasError {
+			m.hasError = false
+			m.errorMessage = ""
+		}
+	}
+	tree.scroll(jsonTreeHeight(m))
+	return m, nil
+}
+
+func jsonTreeHeight(m model) int {
+	return max(m.termHeight-10, 3)
+}
+
+func UpdateReqPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.editingBodyFields.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.editing = false
+				m.editingBodyFields.Blur()
+				return m, nil
+			case "enter":
+				field := m.BodyFields[m.pointer]
+				field.Value = m.editingBodyFields.Value()
+				if err := m.store.UpdateBodyField(m.SelectedApi.ID, field); err != nil {
+					return m, showErrorCommand("Failed to edit body field: " + err.Error())
 				}
-				m.BodyFields = newBodyFields
+				m.savedRequest(m.SelectedApi.ID)
 				m.editing = false
 				m.editingBodyFields.Blur()
 			}
@@ -452,12 +1106,10 @@ func UpdateReqPage(m model, msg tea.Msg) (model, tea.Cmd) {
 					Key:   newBodyFieldKey,
 					Value: "",
 				}
-				m.BodyFields = append(m.BodyFields, newBodyFiled)
-				newBodyFields, err := addBodyField(m.storage, m.collectionIndex, m.ApiIndex, m.BodyFields)
-				if err != nil {
+				if err := m.store.AddBodyField(m.SelectedApi.ID, newBodyFiled); err != nil {
 					return m, showErrorCommand("Failed to add body field: " + err.Error())
 				}
-				m.BodyFields = newBodyFields
+				m.savedRequest(m.SelectedApi.ID)
 				m.newBodyFieldInput.SetValue("")
 				m.newBodyFieldInput.Blur()
 			}
@@ -470,12 +1122,12 @@ func UpdateReqPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.bodyFiledValueInput.Blur()
 				m.bodyFiledValueInput.SetValue("")
 			case "enter":
-				newBodyFieldValue := m.bodyFiledValueInput.Value()
-				m.BodyFields[m.pointer].Value = newBodyFieldValue
-				_, err := addBodyField(m.storage, m.collectionIndex, m.ApiIndex, m.BodyFields)
-				if err != nil {
+				field := m.BodyFields[m.pointer]
+				field.Value = m.bodyFiledValueInput.Value()
+				if err := m.store.UpdateBodyField(m.SelectedApi.ID, field); err != nil {
 					return m, showErrorCommand("Failed to add body field value: " + err.Error())
 				}
+				m.savedRequest(m.SelectedApi.ID)
 				m.bodyFiledValueInput.SetValue("")
 				m.bodyFiledValueInput.Blur()
 			}
@@ -485,10 +1137,7 @@ func UpdateReqPage(m model, msg tea.Msg) (model, tea.Cmd) {
 
 		switch msg.String() {
 		case "enter":
-			m.CurrentPage = LoadingPage
-			m.apiResponse = PostAPiFunc(m)
-			m.Responses, _ = HandleJson(m.apiResponse)
-			return m, postApiCommand(m)
+			return m, m.postApiCommand()
 
 		case "v":
 			m.bodyFiledValueInput.Focus()
@@ -504,15 +1153,21 @@ func UpdateReqPage(m model, msg tea.Msg) (model, tea.Cmd) {
 			if m.pointer < len(m.BodyFields)-1 {
 				m.pointer++
 			}
+		case "t":
+			if len(m.BodyFields) > 0 {
+				field := m.BodyFields[m.pointer]
+				field.Disabled = !field.Disabled
+				if err := m.store.UpdateBodyField(m.SelectedApi.ID, field); err != nil {
+					return m, showErrorCommand("Failed to toggle body field: " + err.Error())
+				}
+				m.savedRequest(m.SelectedApi.ID)
+			}
 		case "d":
 			if len(m.BodyFields) > 0 {
-				selectedBodyField := m.BodyFields[m.pointer]
-				newBodyFields, err := deleteBodyField(selectedBodyField, m.storage, m.collectionIndex, m.ApiIndex)
-				if err != nil {
+				if err := m.store.DeleteBodyField(m.SelectedApi.ID, m.BodyFields[m.pointer].ID); err != nil {
 					return m, showErrorCommand("Failed to delete body field: " + err.Error())
 				}
-
-				m.BodyFields = newBodyFields
+				m.savedRequest(m.SelectedApi.ID)
 				if m.pointer >= len(m.BodyFields) && m.pointer > 0 {
 					m.pointer--
 				}
@@ -549,10 +1204,12 @@ func UpdateHeadersPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.editingHeader.Blur()
 				return m, nil
 			case "enter":
-				m.Headers[m.pointer].Value = m.editingHeader.Value()
-				if err := addHeader(m.Headers, m.storage, m.collectionIndex, m.ApiIndex); err != nil {
+				header := m.Headers[m.pointer]
+				header.Value = m.editingHeader.Value()
+				if err := m.store.UpdateHeader(m.headersOwnerID(), header); err != nil {
 					return m, showErrorCommand("Failed to add new header: " + err.Error())
 				}
+				m.savedHeaders()
 				m.editing = false
 				m.editingHeader.Blur()
 			}
@@ -571,10 +1228,10 @@ func UpdateHeadersPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				newHeder := Header{
 					Key: headerKey,
 				}
-				m.Headers = append(m.Headers, newHeder)
-				if err := addHeader(m.Headers, m.storage, m.collectionIndex, m.ApiIndex); err != nil {
+				if err := m.store.AddHeader(m.headersOwnerID(), newHeder); err != nil {
 					return m, showErrorCommand("Failed to add Header: " + err.Error())
 				}
+				m.savedHeaders()
 				m.addHeaderKey.SetValue("")
 				m.addHeaderKey.Blur()
 			}
@@ -588,10 +1245,12 @@ func UpdateHeadersPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.addHeaderValue.Blur()
 				return m, nil
 			case "enter":
-				m.Headers[m.pointer].Value = m.addHeaderValue.Value()
-				if err := addHeader(m.Headers, m.storage, m.collectionIndex, m.ApiIndex); err != nil {
+				header := m.Headers[m.pointer]
+				header.Value = m.addHeaderValue.Value()
+				if err := m.store.UpdateHeader(m.headersOwnerID(), header); err != nil {
 					return m, showErrorCommand("Failed to add header value: " + err.Error())
 				}
+				m.savedHeaders()
 				m.addHeaderValue.SetValue("")
 				m.addHeaderValue.Blur()
 			}
@@ -601,11 +1260,7 @@ func UpdateHeadersPage(m model, msg tea.Msg) (model, tea.Cmd) {
 
 		switch msg.String() {
 		case "esc":
-			m.CurrentPage = CollectionPage
-			m.pointer = m.ApiIndex
-
-			m.SelectedApi = m.Apis[m.ApiIndex]
-			m.Headers = m.SelectedApi.Headers
+			m.backToCollection()
 
 		case ":":
 			m.addHeaderKey.Focus()
@@ -613,14 +1268,22 @@ func UpdateHeadersPage(m model, msg tea.Msg) (model, tea.Cmd) {
 		case "enter":
 			m.addHeaderValue.Focus()
 
+		case "t":
+			if len(m.Headers) > 0 {
+				header := m.Headers[m.pointer]
+				header.Disabled = !header.Disabled
+				if err := m.store.UpdateHeader(m.headersOwnerID(), header); err != nil {
+					return m, showErrorCommand("Failed to toggle header: " + err.Error())
+				}
+				m.savedHeaders()
+			}
+
 		case "d":
 			if len(m.Headers) > 0 {
-				selectedHeader := m.Headers[m.pointer]
-				newHeaders, err := deleteHeader(selectedHeader, m.storage, m.collectionIndex, m.ApiIndex)
-				if err != nil {
+				if err := m.store.DeleteHeader(m.headersOwnerID(), m.Headers[m.pointer].ID); err != nil {
 					return m, showErrorCommand("Failed to delete header: " + err.Error())
 				}
-				m.Headers = newHeaders
+				m.savedHeaders()
 				if m.pointer >= len(m.Headers) && m.pointer > 0 {
 					m.pointer--
 				}
@@ -666,10 +1329,12 @@ func UpdateQueryParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.editingQueryParams.Blur()
 				return m, nil
 			case "enter":
-				m.QueryParams[m.pointer].Value = m.editingQueryParams.Value()
-				if err := addQueryParam(m.QueryParams, m.storage, m.collectionIndex, m.ApiIndex); err != nil {
+				param := m.QueryParams[m.pointer]
+				param.Value = m.editingQueryParams.Value()
+				if err := m.store.UpdateQueryParam(m.SelectedApi.ID, param); err != nil {
 					return m, showErrorCommand("Failed to edit query params: " + err.Error())
 				}
+				m.savedRequest(m.SelectedApi.ID)
 				m.editing = false
 				m.editingQueryParams.Blur()
 			}
@@ -688,10 +1353,10 @@ func UpdateQueryParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
 					Key:   key,
 					Value: "",
 				}
-				m.QueryParams = append(m.QueryParams, newQueryParam)
-				if err := addQueryParam(m.QueryParams, m.storage, m.collectionIndex, m.ApiIndex); err != nil {
+				if err := m.store.AddQueryParam(m.SelectedApi.ID, newQueryParam); err != nil {
 					return m, showErrorCommand("Failed to add query param: " + err.Error())
 				}
+				m.savedRequest(m.SelectedApi.ID)
 				m.addQueryParamsKey.SetValue("")
 				m.addQueryParamsKey.Blur()
 			}
@@ -705,10 +1370,12 @@ func UpdateQueryParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.addQueryParamsValue.SetValue("")
 				m.addQueryParamsValue.Blur()
 			case "enter":
-				m.QueryParams[m.pointer].Value = m.addQueryParamsValue.Value()
-				if err := addQueryParam(m.QueryParams, m.storage, m.collectionIndex, m.ApiIndex); err != nil {
+				param := m.QueryParams[m.pointer]
+				param.Value = m.addQueryParamsValue.Value()
+				if err := m.store.UpdateQueryParam(m.SelectedApi.ID, param); err != nil {
 					return m, showErrorCommand("Failed to add query param value: " + err.Error())
 				}
+				m.savedRequest(m.SelectedApi.ID)
 				m.addQueryParamsValue.SetValue("")
 				m.addQueryParamsValue.Blur()
 			}
@@ -718,8 +1385,7 @@ func UpdateQueryParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
 
 		switch msg.String() {
 		case "esc":
-			m.CurrentPage = CollectionPage
-			m.pointer = m.ApiIndex
+			m.backToCollection()
 		case ":":
 			m.addQueryParamsKey.Focus()
 		case "enter":
@@ -738,14 +1404,21 @@ func UpdateQueryParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
 			m.editingQueryParams = textinput.New()
 			m.editingQueryParams.SetValue(value)
 			m.editingQueryParams.Focus()
+		case "t":
+			if len(m.QueryParams) > 0 {
+				param := m.QueryParams[m.pointer]
+				param.Disabled = !param.Disabled
+				if err := m.store.UpdateQueryParam(m.SelectedApi.ID, param); err != nil {
+					return m, showErrorCommand("Failed to toggle query param: " + err.Error())
+				}
+				m.savedRequest(m.SelectedApi.ID)
+			}
 		case "d":
 			if len(m.QueryParams) > 0 {
-				selectedQueryParam := m.QueryParams[m.pointer]
-				newQueryParams, err := deleteQueryParam(selectedQueryParam, m.storage, m.collectionIndex, m.ApiIndex)
-				if err != nil {
+				if err := m.store.DeleteQueryParam(m.SelectedApi.ID, m.QueryParams[m.pointer].ID); err != nil {
 					return m, showErrorCommand("Failed to delete query param: " + err.Error())
 				}
-				m.QueryParams = newQueryParams
+				m.savedRequest(m.SelectedApi.ID)
 				if m.pointer >= len(m.QueryParams) && m.pointer > 0 {
 					m.pointer--
 				}
@@ -762,12 +1435,217 @@ func UpdateQueryParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	return m, nil
 }
 
+func UpdatePathParamsPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+
+		if m.editingPathParam.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.editing = false
+				m.editingPathParam.Blur()
+				return m, nil
+			case "enter":
+				api := m.SelectedApi
+				api.PathParams = append([]PathParam(nil), api.PathParams...)
+				api.PathParams[m.pointer].Value = m.editingPathParam.Value()
+				if err := m.store.UpdateRequest(api); err != nil {
+					return m, showErrorCommand("Failed to edit path param: " + err.Error())
+				}
+				m.savedRequest(api.ID)
+				m.editing = false
+				m.editingPathParam.Blur()
+				return m, nil
+			}
+			m.editingPathParam, cmd = m.editingPathParam.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.backToCollection()
+		case "up", "k":
+			if m.pointer > 0 {
+				m.pointer--
+			}
+		case "down", "j":
+			if m.pointer < len(m.PathParams)-1 {
+				m.pointer++
+			}
+		case "enter", "e":
+			if len(m.PathParams) > 0 {
+				m.editing = true
+				m.editingPathParam = textinput.New()
+				m.editingPathParam.Placeholder = "Value or {{variable}}..."
+				m.editingPathParam.SetValue(m.PathParams[m.pointer].Value)
+				m.editingPathParam.Focus()
+			}
+
+		case "x":
+			if m.hasError {
+				m.hasError = false
+				m.errorMessage = ""
+				return m, nil
+			}
+		}
+	}
+	return m, nil
+}
+
+func UpdateCookiesPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	cookies := m.SelectedCollection.Cookies
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+
+		if m.editingCookie.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.editing = false
+				m.editingCookie.Blur()
+				return m, nil
+			case "enter":
+				updated := append([]Cookie(nil), cookies...)
+				updated[m.pointer].Value = m.editingCookie.Value()
+				if err := m.store.SetCookies(m.SelectedCollection.ID, updated); err != nil {
+					return m, showErrorCommand("Failed to edit cookie: " + err.Error())
+				}
+				m.refreshCollection()
+				m.editing = false
+				m.editingCookie.Blur()
+				return m, nil
+			}
+			m.editingCookie, cmd = m.editingCookie.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.backToCollection()
+		case "up", "k":
+			if m.pointer > 0 {
+				m.pointer--
+			}
+		case "down", "j":
+			if m.pointer < len(cookies)-1 {
+				m.pointer++
+			}
+		case "e", "enter":
+			if len(cookies) > 0 {
+				m.editing = true
+				m.editingCookie = textinput.New()
+				m.editingCookie.SetValue(cookies[m.pointer].Value)
+				m.editingCookie.Focus()
+			}
+		case "d":
+			if len(cookies) > 0 {
+				updated := append(append([]Cookie(nil), cookies[:m.pointer]...), cookies[m.pointer+1:]...)
+				if err := m.store.SetCookies(m.SelectedCollection.ID, updated); err != nil {
+					return m, showErrorCommand("Failed to delete cookie: " + err.Error())
+				}
+				m.refreshCollection()
+				if m.pointer >= len(updated) && m.pointer > 0 {
+					m.pointer--
+				}
+			}
+		case "D":
+			if err := m.store.SetCookies(m.SelectedCollection.ID, nil); err != nil {
+				return m, showErrorCommand("Failed to clear cookies: " + err.Error())
+			}
+			m.refreshCollection()
+			m.pointer = 0
+
+		case "x":
+			if m.hasError {
+				m.hasError = false
+				m.errorMessage = ""
+				return m, nil
+			}
+		}
+	}
+	return m, nil
+}
+
+func UpdateSettingsPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	form := m.settingsForm()
+	rows := form.Rows
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+
+		if m.editingSetting.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.editing = false
+				m.editingSetting.Blur()
+				return m, nil
+			case "enter":
+				if err := rows[m.pointer].set(m.editingSetting.Value()); err != nil {
+					return m, showErrorCommand("Failed to edit setting: " + err.Error())
+				}
+				if err := form.save(m.store); err != nil {
+					return m, showErrorCommand("Failed to edit setting: " + err.Error())
+				}
+				m.savedSettingsForm()
+				m.editing = false
+				m.editingSetting.Blur()
+				return m, nil
+			}
+			m.editingSetting, cmd = m.editingSetting.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "esc":
+			if m.settingsScope == globalScope {
+				m.CurrentPage = HomePage
+				m.pointer = m.collectionIndex
+				return m, nil
+			}
+			m.backToCollection()
+		case "up", "k":
+			if m.pointer > 0 {
+				m.pointer--
+			}
+		case "down", "j":
+			if m.pointer < len(rows)-1 {
+				m.pointer++
+			}
+		case "enter", "e", " ":
+			row := rows[m.pointer]
+			if row.toggle != nil {
+				row.toggle()
+				if err := form.save(m.store); err != nil {
+					return m, showErrorCommand("Failed to edit setting: " + err.Error())
+				}
+				m.savedSettingsForm()
+				return m, nil
+			}
+			m.editing = true
+			m.editingSetting = textinput.New()
+			m.editingSetting.Placeholder = "Empty for the default"
+			m.editingSetting.SetValue(row.Value)
+			m.editingSetting.Focus()
+
+		case "x":
+			if m.hasError {
+				m.hasError = false
+				m.errorMessage = ""
+				return m, nil
+			}
+		}
+	}
+	return m, nil
+}
+
 func UpdateLoadingPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
-			m.CurrentPage = CollectionPage
+			m.cancelPendingRequest()
+			m.backToCollection()
 		}
 	}
 	return m, nil
@@ -781,7 +1659,7 @@ func UpdateResponsePage(m model, msg tea.Msg) (model, tea.Cmd) {
 			switch msg.String() {
 			case "esc":
 				m.CurrentPage = ApiPage
-				m.pointer = m.ApiIndex
+				m.pointer = 0
 			case "r":
 				m.VariablesFocus = false
 				m.pointer = 0
@@ -795,12 +1673,10 @@ func UpdateResponsePage(m model, msg tea.Msg) (model, tea.Cmd) {
 				}
 			case "d":
 				if len(m.LocalVariables) > 0 {
-					selectedVariable := m.LocalVariables[m.pointer]
-					newLocalVariables, err := deleteLocalVariable(selectedVariable, m.storage, m.collectionIndex)
-					if err != nil {
+					if err := m.store.DeleteVariable(m.SelectedCollection.ID, m.LocalVariables[m.pointer].ID); err != nil {
 						return m, showErrorCommand("Failed to delete Local Variable : " + err.Error())
 					}
-					m.LocalVariables = newLocalVariables
+					m.savedCollection()
 					if m.pointer >= len(m.LocalVariables) && m.pointer > 0 {
 						m.pointer--
 					}
@@ -812,7 +1688,7 @@ func UpdateResponsePage(m model, msg tea.Msg) (model, tea.Cmd) {
 			switch msg.String() {
 			case "esc":
 				m.CurrentPage = ApiPage
-				m.pointer = m.ApiIndex
+				m.pointer = 0
 
 			case "up", "k":
 				if m.pointer > 0 {
@@ -828,11 +1704,10 @@ func UpdateResponsePage(m model, msg tea.Msg) (model, tea.Cmd) {
 					Key:   selectedResponse.Key,
 					Value: selectedResponse.Value,
 				}
-				m.LocalVariables = append(m.LocalVariables, newLocalVariable)
-				err := addLocalVariable(m.storage, m.collectionIndex, m.LocalVariables)
-				if err != nil {
+				if err := m.store.AddVariable(m.SelectedCollection.ID, newLocalVariable); err != nil {
 					return m, showErrorCommand("Failed to add local Variable: " + err.Error())
 				}
+				m.savedCollection()
 			case "v":
 				m.VariablesFocus = true
 				m.pointer = 0
@@ -859,10 +1734,12 @@ func UpdateVariablesPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.editingLocalVariables.Blur()
 				return m, nil
 			case "enter":
-				m.LocalVariables[m.pointer].Value = m.editingLocalVariables.Value()
-				if err := addLocalVariable(m.storage, m.collectionIndex, m.LocalVariables); err != nil {
+				variable := m.LocalVariables[m.pointer]
+				variable.Value = m.editingLocalVariables.Value()
+				if err := m.store.UpdateVariable(m.variablesOwnerID(), variable); err != nil {
 					return m, showErrorCommand("Failed to edit Local Variable : " + err.Error())
 				}
+				m.savedCollection()
 				m.editing = false
 				m.editingLocalVariables.Blur()
 			}
@@ -875,11 +1752,12 @@ func UpdateVariablesPage(m model, msg tea.Msg) (model, tea.Cmd) {
 				m.addVariableValue.Blur()
 				m.addVariableValue.SetValue("")
 			case "enter":
-				m.LocalVariables[m.pointer].Value = m.addVariableValue.Value()
-				err := addLocalVariable(m.storage, m.collectionIndex, m.LocalVariables)
-				if err != nil {
+				variable := m.LocalVariables[m.pointer]
+				variable.Value = m.addVariableValue.Value()
+				if err := m.store.UpdateVariable(m.variablesOwnerID(), variable); err != nil {
 					return m, showErrorCommand("Failed to add local Variable: " + err.Error())
 				}
+				m.savedCollection()
 				m.addVariableValue.Blur()
 				m.addVariableValue.SetValue("")
 			}
@@ -896,11 +1774,10 @@ func UpdateVariablesPage(m model, msg tea.Msg) (model, tea.Cmd) {
 					Key:   m.addVariableKey.Value(),
 					Value: "",
 				}
-				m.LocalVariables = append(m.LocalVariables, NewResponse)
-				err := addLocalVariable(m.storage, m.collectionIndex, m.LocalVariables)
-				if err != nil {
+				if err := m.store.AddVariable(m.variablesOwnerID(), NewResponse); err != nil {
 					return m, showErrorCommand("Failed to add local Variable: " + err.Error())
 				}
+				m.savedCollection()
 				m.addVariableKey.Blur()
 				m.addVariableKey.SetValue("")
 			}
@@ -909,7 +1786,7 @@ func UpdateVariablesPage(m model, msg tea.Msg) (model, tea.Cmd) {
 		}
 		switch msg.String() {
 		case "esc":
-			m.CurrentPage = CollectionPage
+			m.backToCollection()
 		case "up", "k":
 			if m.pointer > 0 {
 				m.pointer--
@@ -918,14 +1795,21 @@ func UpdateVariablesPage(m model, msg tea.Msg) (model, tea.Cmd) {
 			if m.pointer < len(m.LocalVariables)-1 {
 				m.pointer++
 			}
+		case "t":
+			if len(m.LocalVariables) > 0 {
+				variable := m.LocalVariables[m.pointer]
+				variable.Disabled = !variable.Disabled
+				if err := m.store.UpdateVariable(m.variablesOwnerID(), variable); err != nil {
+					return m, showErrorCommand("Failed to toggle Local Variable : " + err.Error())
+				}
+				m.savedCollection()
+			}
 		case "d":
 			if len(m.LocalVariables) > 0 {
-				selectedVariable := m.LocalVariables[m.pointer]
-				newLocalVariables, err := deleteLocalVariable(selectedVariable, m.storage, m.collectionIndex)
-				if err != nil {
+				if err := m.store.DeleteVariable(m.variablesOwnerID(), m.LocalVariables[m.pointer].ID); err != nil {
 					return m, showErrorCommand("Failed to delete Local Variable : " + err.Error())
 				}
-				m.LocalVariables = newLocalVariables
+				m.savedCollection()
 				if m.pointer >= len(m.LocalVariables) && m.pointer > 0 {
 					m.pointer--
 				}
@@ -944,3 +1828,300 @@ func UpdateVariablesPage(m model, msg tea.Msg) (model, tea.Cmd) {
 	}
 	return m, nil
 }
+
+func UpdateDocsPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.descriptionInput.Focused() {
+			switch msg.String() {
+			case "esc":
+				m.descriptionInput.Blur()
+				m.refreshDocs()
+				return m, nil
+			case "ctrl+s":
+				var err error
+				if m.docsApiID != "" {
+					api := m.SelectedApi
+					api.Description = m.descriptionInput.Value()
+					err = m.store.UpdateRequest(api)
+				} else {
+					err = m.store.SetCollectionDescription(m.SelectedCollection.ID, m.descriptionInput.Value())
+				}
+				if err != nil {
+					return m, showErrorCommand("Failed to save description: " + err.Error())
+				}
+				if m.docsApiID != "" {
+					m.savedRequest(m.SelectedApi.ID)
+				} else {
+					m.savedCollection()
+				}
+				m.descriptionInput.Blur()
+				m.refreshDocs()
+				return m, nil
+			}
+
+			m.descriptionInput, cmd = m.descriptionInput.Update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "esc":
+			if m.docsReturn == HomePage {
+				m.CurrentPage = HomePage
+				m.pointer = m.collectionIndex
+			} else {
+				m.backToCollection()
+			}
+		case "e":
+			m.descriptionInput.SetValue(m.docsDescription())
+			m.descriptionInput.SetWidth(max(m.termWidth-4, 20))
+			m.descriptionInput.SetHeight(max(m.termHeight-10, 5))
+			m.refreshDocs()
+			return m, m.descriptionInput.Focus()
+		case "up", "k":
+			m.docsViewport.LineUp(1)
+		case "down", "j":
+			m.docsViewport.LineDown(1)
+		case "pgup", "b":
+			m.docsViewport.ViewUp()
+		case "pgdown", "f", " ":
+			m.docsViewport.ViewDown()
+		case "x":
+			if m.hasError {
+				m.hasError = false
+				m.errorMessage = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+// openDocs shows the docs pane for a request, or for the selected collection
+// when apiID is empty.
+func (m *model) openDocs(apiID string) {
+	m.docsApiID = apiID
+	m.docsReturn = m.CurrentPage
+	m.CurrentPage = DocsPage
+	m.docsViewport = viewport.New(m.termWidth, max(m.termHeight-6, 5))
+	m.refreshDocs()
+}
+
+func (m model) docsDescription() string {
+	if m.docsApiID != "" {
+		return m.SelectedApi.Description
+	}
+	return m.SelectedCollection.Description
+}
+
+func (m *model) refreshDocs() {
+	m.docsViewport.Width = m.termWidth
+	m.docsViewport.Height = max(m.termHeight-6, 5)
+	if m.descriptionInput.Focused() {
+		m.docsViewport.Height = 0
+	}
+	m.docsViewport.SetContent(renderMarkdown(m.docsDescription(), m.termWidth-4))
+}
+
+func UpdateFinderPage(m model, msg tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			m.finderInput.Blur()
+			m.CurrentPage = m.finderReturn
+			m.pointer = 0
+			if m.finderReturn == HomePage {
+				m.pointer = m.collectionIndex
+			}
+			return m, nil
+		case "up", "ctrl+k":
+			if m.pointer > 0 {
+				m.pointer--
+			}
+			return m, nil
+		case "down", "ctrl+j":
+			if m.pointer < len(m.finderResults)-1 {
+				m.pointer++
+			}
+			return m, nil
+		case "enter":
+			if len(m.finderResults) > 0 {
+				m.jumpToRequest(m.finderResults[m.pointer])
+			}
+			return m, nil
+		}
+
+		m.finderInput, cmd = m.finderInput.Update(msg)
+		m.finderResults = searchRequests(m.store, finderEntries(m.storage), m.finderInput.Value())
+		m.pointer = 0
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *model) openFinder() tea.Cmd {
+	m.finderReturn = m.CurrentPage
+	m.CurrentPage = FinderPage
+	m.pointer = 0
+	m.finderInput.SetValue("")
+	m.finderResults = finderEntries(m.storage)
+	return m.finderInput.Focus()
+}
+
+// jumpToRequest opens the request's collection with its folders expanded and
+// the cursor on the request.
+func (m *model) jumpToRequest(entry finderEntry) {
+	i := indexByID(m.Collections, entry.CollectionID)
+	if i < 0 {
+		return
+	}
+	m.finderInput.Blur()
+	m.collectionIndex = i
+	m.SelectedCollection = m.Collections[i]
+	m.Apis = allApis(m.SelectedCollection)
+	m.SelectedApi = entry.Api
+	path, _ := folderPath(m.SelectedCollection.Folders, entry.Api.ID)
+	for _, folder := range path {
+		m.expanded[folder.ID] = true
+	}
+	m.backToCollection()
+}
+
+func (m *model) applyStorage(storage Storage) {
+	m.storage = storage
+	m.Collections = m.storage.Collections
+
+	if m.CurrentPage == CollectionPage || m.CurrentPage == HeadersPage ||
+		m.CurrentPage == RequestPage || m.CurrentPage == QueryParamsPage ||
+		m.CurrentPage == ResponsePage || m.CurrentPage == VariablesPage ||
+		m.CurrentPage == ApiPage || m.CurrentPage == DocsPage ||
+		m.CurrentPage == PathParamsPage || m.CurrentPage == CookiesPage ||
+		m.CurrentPage == SettingsPage {
+
+		if i := indexByID(m.Collections, m.SelectedCollection.ID); i >= 0 {
+			m.collectionIndex = i
+			m.SelectedCollection = m.Collections[i]
+			m.Apis = allApis(m.SelectedCollection)
+			m.LocalVariables = m.SelectedCollection.LocalVariables
+
+			if j := indexByID(m.Apis, m.SelectedApi.ID); j >= 0 {
+				m.SelectedApi = m.Apis[j]
+
+				m.Headers = m.SelectedApi.Headers
+				m.BodyFields = m.SelectedApi.BodyField
+				m.QueryParams = m.SelectedApi.QueryParams
+				m.PathParams = m.SelectedApi.PathParams
+			}
+
+			if m.SelectedFolder.ID != "" {
+				if folder := findFolder(m.SelectedCollection.Folders, m.SelectedFolder.ID); folder != nil {
+					m.SelectedFolder = *folder
+					m.Headers = folder.Headers
+					m.LocalVariables = folder.LocalVariables
+				}
+			}
+		}
+	}
+
+	if m.CurrentPage == DocsPage && !m.descriptionInput.Focused() {
+		m.refreshDocs()
+	}
+}
+
+// collectionOfApi returns the ID of the collection holding a request.
+func collectionOfApi(storage Storage, apiID string) string {
+	for _, collection := range storage.Collections {
+		if indexByID(allApis(collection), apiID) >= 0 {
+			return collection.ID
+		}
+	}
+	return ""
+}
+
+// headersOwnerID is the folder or request whose headers the headers page edits.
+func (m model) headersOwnerID() string {
+	if m.SelectedFolder.ID != "" {
+		return m.SelectedFolder.ID
+	}
+	return m.SelectedApi.ID
+}
+
+// variablesOwnerID is the folder or collection whose variables the variables page edits.
+func (m model) variablesOwnerID() string {
+	if m.SelectedFolder.ID != "" {
+		return m.SelectedFolder.ID
+	}
+	return m.SelectedCollection.ID
+}
+
+// refreshStorage reloads the whole workspace, for edits that change the
+// collection list or the global settings.
+func (m *model) refreshStorage() bool {
+	storage, err := m.store.Load()
+	if err != nil {
+		m.errorMessage = "Failed to reload data: " + err.Error()
+		m.hasError = true
+		return false
+	}
+	m.applyStorage(storage)
+	return true
+}
+
+// refreshCollections reloads only the given collections, so an edit inside
+// one does not read back the whole workspace.
+func (m *model) refreshCollections(ids ...string) bool {
+	storage := m.storage
+	storage.Collections = slices.Clone(storage.Collections)
+	for _, id := range ids {
+		i := indexByID(storage.Collections, id)
+		if i < 0 {
+			continue
+		}
+		collection, err := m.store.LoadCollection(id)
+		if err != nil {
+			m.errorMessage = "Failed to reload data: " + err.Error()
+			m.hasError = true
+			return false
+		}
+		storage.Collections[i] = collection
+	}
+	m.applyStorage(storage)
+	return true
+}
+
+// refreshCollection reloads the selected collection.
+func (m *model) refreshCollection() bool {
+	return m.refreshCollections(m.SelectedCollection.ID)
+}
+
+// savedHeaders follows an edit on the headers page, whose headers belong to
+// a request or a folder.
+func (m *model) savedHeaders() {
+	if m.SelectedFolder.ID != "" {
+		m.savedCollection()
+		return
+	}
+	m.savedRequest(m.SelectedApi.ID)
+}
+
+func (m *model) updateApiLine(apiID string, input string) error {
+	i := indexByID(m.Apis, apiID)
+	if i < 0 {
+		return fmt.Errorf("request not found")
+	}
+	method, url, err := parseApiInput(input)
+	if err != nil {
+		return err
+	}
+	api := withURL(m.Apis[i], url)
+	api.Method = method
+	if err := m.store.UpdateRequest(api); err != nil {
+		return err
+	}
+	m.savedRequest(api.ID)
+	return nil
+}
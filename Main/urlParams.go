@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pathParamPattern matches ":name" path segments such as /users/:id/orders.
+var pathParamPattern = regexp.MustCompile(`/:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// placeholderPattern matches {{variable}} placeholders.
+var placeholderPattern = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// splitURL separates a pasted URL into its base and query parameters. It
+// works on the raw text so {{variables}} survive untouched.
+func splitURL(raw string) (string, []QueryParam) {
+	raw = strings.TrimSpace(raw)
+	base, query, found := strings.Cut(raw, "?")
+	if !found {
+		return raw, nil
+	}
+	query, _, _ = strings.Cut(query, "#")
+
+	var params []QueryParam
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		params = append(params, QueryParam{Key: unescapeQuery(key), Value: unescapeQuery(value)})
+	}
+	return base, params
+}
+
+func unescapeQuery(text string) string {
+	if unescaped, err := url.QueryUnescape(text); err == nil {
+		return unescaped
+	}
+	return text
+}
+
+// withURL sets the request URL from user input, moving any query string into
+// QueryParams and picking up :name path parameters.
+func withURL(api Api, raw string) Api {
+	base, params := splitURL(raw)
+	api.Url = base
+	api.QueryParams = syncQueryParams(api.QueryParams, params)
+	api.PathParams = syncPathParams(api.PathParams, pathParamNames(base))
+	return api
+}
+
+// syncQueryParams makes the enabled params match the ones parsed from the URL,
+// keeping IDs of params that were already there. Disabled params are not in
+// the URL, so they are kept as they are.
+func syncQueryParams(existing []QueryParam, parsed []QueryParam) []QueryParam {
+	used := map[int]bool{}
+	result := []QueryParam{}
+	for _, param := range parsed {
+		for i, old := range existing {
+			if !used[i] && old.isEnabled() && old.Key == param.Key {
+				param.ID = old.ID
+				used[i] = true
+				break
+			}
+		}
+		result = append(result, param)
+	}
+	for _, old := range existing {
+		if old.Disabled {
+			result = append(result, old)
+		}
+	}
+	return result
+}
+
+func pathParamNames(base string) []string {
+	path := base
+	if _, rest, found := strings.Cut(base, "://"); found {
+		path = rest
+	}
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i:]
+	} else {
+		return nil
+	}
+
+	var names []string
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+func syncPathParams(existing []PathParam, names []string) []PathParam {
+	result := []PathParam{}
+	for _, name := range names {
+		param := PathParam{Key: name}
+		for _, old := range existing {
+			if old.Key == name {
+				param = old
+				break
+			}
+		}
+		result = append(result, param)
+	}
+	return result
+}
+
+// applyPathParams substitutes :name segments with their escaped values.
+// Params without a value are left in place so the problem stays visible.
+func applyPathParams(rawURL string, params []PathParam, variables []LocalVariable) string {
+	values := map[string]string{}
+	for _, param := range params {
+		if param.Value != "" {
+			values[param.Key] = url.PathEscape(replaceVariables(param.Value, variables))
+		}
+	}
+	return pathParamPattern.ReplaceAllStringFunc(rawURL, func(segment string) string {
+		if value, ok := values[segment[2:]]; ok {
+			return "/" + value
+		}
+		return segment
+	})
+}
+
+// displayURL shows the request URL with its enabled query params, as the
+// user would type or paste it.
+func displayURL(api Api) string {
+	params := enabledItems(api.QueryParams)
+	if len(params) == 0 {
+		return api.Url
+	}
+	var pairs []string
+	for _, param := range params {
+		pairs = append(pairs, escapeQueryText(param.Key)+"="+escapeQueryText(param.Value))
+	}
+	separator := "?"
+	if strings.Contains(api.Url, "?") {
+		separator = "&"
+	}
+	return api.Url + separator + strings.Join(pairs, "&")
+}
+
+// escapeQueryText escapes a query key or value so splitURL reads it back
+// unchanged. {{variable}} placeholders are kept as they are.
+func escapeQueryText(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range placeholderPattern.FindAllStringIndex(text, -1) {
+		b.WriteString(url.QueryEscape(text[last:match[0]]))
+		b.WriteString(text[match[0]:match[1]])
+		last = match[1]
+	}
+	b.WriteString(url.QueryEscape(text[last:]))
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDisplayURLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		params []QueryParam
+	}{
+		{"plain", "https://api.example.com/pets", []QueryParam{{Key: "limit", Value: "10"}}},
+		{"ampersand and equals", "https://x/search", []QueryParam{{Key: "q", Value: "a&b=c"}}},
+		{"plus and spaces", "https://x/t", []QueryParam{{Key: "tz", Value: "+01:00"}, {Key: "name", Value: "a b"}}},
+		{"hash and percent", "https://x/t", []QueryParam{{Key: "frag", Value: "#top"}, {Key: "pct", Value: "100%"}}},
+		{"escaped key", "https://x/t", []QueryParam{{Key: "a[]&b", Value: "1"}}},
+		{"variables", "{{base}}/users/:id", []QueryParam{{Key: "token", Value: "{{token}}"}, {Key: "q", Value: "x&{{term}}+1"}}},
+		{"empty value", "https://x/t", []QueryParam{{Key: "flag", Value: ""}}},
+		{"unicode", "https://x/t", []QueryParam{{Key: "city", Value: "Zürich"}}},
+		{"no params", "https://x/t", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := Api{Url: tt.url}
+			api.QueryParams = append(api.QueryParams, tt.params...)
+
+			got := withURL(Api{}, displayURL(api))
+			if got.Url != api.Url {
+				t.Errorf("Url = %q, want %q", got.Url, api.Url)
+			}
+			want := api.QueryParams
+			if want == nil {
+				want = []QueryParam{}
+			}
+			if !reflect.DeepEqual(got.QueryParams, want) {
+				t.Errorf("displayURL = %q\nparams = %+v\nwant %+v", displayURL(api), got.QueryParams, want)
+			}
+		})
+	}
+}
+
+func TestDisplayURLSkipsDisabledParams(t *testing.T) {
+	api := Api{Url: "https://x/t", QueryParams: []QueryParam{
+		{Key: "on", Value: "1"},
+		{Key: "off", Value: "2", Disabled: true},
+	}}
+	if got := displayURL(api); got != "https://x/t?on=1" {
+		t.Errorf("displayURL = %q", got)
+	}
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"regexp"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -29,6 +31,18 @@ func (m model) View() string {
 		return ResponsePageView(m)
 	case VariablesPage:
 		return VariablesPageView(m)
+	case DocsPage:
+		return DocsPageView(m)
+	case FinderPage:
+		return FinderPageView(m)
+	case PathParamsPage:
+		return PathParamsPageView(m)
+	case CookiesPage:
+		return CookiesPageView(m)
+	case SettingsPage:
+		return SettingsPageView(m)
+	case HistoryPage:
+		return HistoryPageView(m)
 	}
 	return ""
 }
@@ -50,6 +64,9 @@ func Homepage(m model) string {
 
 	for i := 0; i < len(collections); i++ {
 		text := collections[i].Name
+		if collections[i].ID == m.clipboardCollectionID {
+			text += clipboardTag(m)
+		}
 
 		if i == m.pointer && m.editing {
 			line := style4.Render("> ") + m.editingCollection.View() + "\n"
@@ -73,8 +90,14 @@ func Homepage(m model) string {
 		errorWarning = line
 	}
 
+	if i := indexByID(collections, m.confirmDeleteID); i >= 0 {
+		errorStyle := errorStyle(m.termWidth)
+		errorWarning = errorStyle.Render(fmt.Sprintf("Delete collection '%s' and its %d requests?\n\nPress 'y' to delete, any other key to cancel",
+			collections[i].Name, len(allApis(collections[i])))) + "\n" + errorWarning
+	}
+
 	leftBox := style3.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.NewCollectionInput.View())) + "\n\n" + errorWarning
-	rightBox := style2.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter -> Open\n\n: -> Add New\n\nd -> Delete\n\ne -> Edit")
+	rightBox := style2.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter -> Open\n\n: -> Add New\n\nd -> Delete\n\ne -> Edit\n\ni -> Docs\n\ny -> Duplicate\n\nJ/K -> Reorder\n\nm/c/p -> Move/Copy/Paste\n\nctrl+z/y -> Undo/Redo\n\nctrl+p -> Find\n\nS -> Settings\n\nH -> History")
 	layout := lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox)
 
 	b.WriteString(layout)
@@ -95,20 +118,40 @@ func Collectionpage(termWidth, termHeight int, m model) string {
 
 	var items []string
 
-	for i := 0; i < len(m.Apis); i++ {
-		api := m.Apis[i]
+	for i, row := range collectionRows(&m.SelectedCollection, m.expanded) {
+		indent := strings.Repeat("  ", row.Depth)
 
 		if i == m.pointer && m.editing {
-			line := style4.Render("> ") + m.editingApi.View() + "\n"
+			editor := m.editingApi.View()
+			if row.Folder != nil {
+				editor = m.editingFolder.View()
+			}
+			line := style4.Render("> ") + indent + editor + "\n"
 			items = append(items, line)
 			continue
 		}
 
-		text := api.Method + " " + api.Url
+		var text string
+		if row.Folder != nil {
+			marker := "▸ "
+			if m.expanded[row.Folder.ID] {
+				marker = "▾ "
+			}
+			text = marker + row.Folder.Name + "/"
+			if row.Folder.Auth != nil {
+				text += " [" + row.Folder.Auth.Type + "]"
+			}
+		} else {
+			text = row.Api.Method + " " + apiTitle(*row.Api)
+			if row.Api.ID == m.clipboardApiID {
+				text += clipboardTag(m)
+			}
+		}
+
 		if i == m.pointer {
-			text = style4.Render("> ") + style5.Render(text+"\n")
+			text = style4.Render("> ") + indent + style5.Render(text+"\n")
 		} else {
-			text = "   " + text + "\n"
+			text = "   " + indent + text + "\n"
 		}
 		items = append(items, text)
 	}
@@ -121,23 +164,148 @@ func Collectionpage(termWidth, termHeight int, m model) string {
 		errorWarning = line
 	}
 
-	leftBox := style3.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.NewApiInput.View())) + "\n\n" + errorWarning
-	rightBox := style2.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter -> Open\n\n: -> Add New\n\nd -> Delete\n\ne -> Edit\n\nh -> Headers\n\nq -> QueryParams")
+	if folder := findFolder(m.SelectedCollection.Folders, m.confirmDeleteID); folder != nil {
+		errorStyle := errorStyle(m.termWidth)
+		contents := Collection{Requests: folder.Requests, Folders: folder.Folders}
+		errorWarning = errorStyle.Render(fmt.Sprintf("Delete folder '%s' and its %d requests?\n\nPress 'y' to delete, any other key to cancel",
+			folder.Name, len(allApis(contents)))) + "\n" + errorWarning
+	}
+
+	leftBox := style3.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.NewApiInput.View(), m.NewFolderInput.View(), m.editingAuth.View())) + "\n\n" + errorWarning
+	rightBox := style2.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter -> Open\n\nl/← -> Expand/Collapse\n\n: -> Add New\n\nn -> New Folder\n\nd -> Delete\n\ne -> Edit\n\nh -> Headers\n\nq -> QueryParams\n\nu -> Path Params\n\no -> Cookies\n\ns/S -> Request/Collection Settings\n\nv -> Variables\n\nr -> Name\n\ni -> Docs\n\na -> Folder Auth\n\ny -> Duplicate\n\nJ/K -> Reorder\n\nm/c/p -> Move/Copy/Paste\n\nctrl+z/y -> Undo/Redo\n\nctrl+p -> Find")
 	layout := lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox)
 
 	b.WriteString(layout)
 	return b.String()
 }
 
+func toggleBox(enabled bool) string {
+	if enabled {
+		return "[x] "
+	}
+	return "[ ] "
+}
+
+func clipboardTag(m model) string {
+	if m.clipboardCopy {
+		return " (copying)"
+	}
+	return " (moving)"
+}
+
 func ApipageWithViewport(m model) string {
 	if !m.viewportReady {
 		return "Loading..."
 	}
+	if m.jsonTree != nil {
+		return JSONTreeView(m)
+	}
+
+	if m.filterInput.Focused() || m.filterError != "" {
+		filter := "\n\n| " + m.filterInput.View()
+		if m.filterError != "" {
+			filter += "\n" + StatusErrorStyle.Render(m.filterError)
+		}
+		return m.apiViewport.View() + filter
+	}
+
+	if m.saveInput.Focused() {
+		return m.apiViewport.View() + "\n\n" + m.saveInput.View()
+	}
+	if m.searchInput.Focused() {
+		return m.apiViewport.View() + "\n\n" + m.searchInput.View() + "  " + m.searchStatus()
+	}
+	if m.searchInput.Value() != "" {
+		helpText := HelpTextStyle.Render("\n\n/" + m.searchInput.Value() + "  " + m.searchStatus() + " • n/N: next/prev match • esc: clear search")
+		return m.apiViewport.View() + helpText
+	}
 
-	helpText := HelpTextStyle.Render("\n\n↑/↓ j/k: scroll • space/b: page up/down • g/G: top/bottom • esc: back")
+	helpText := HelpTextStyle.Render("\n\n↑/↓ j/k: scroll • space/b: page up/down • g/G: top/bottom • /: search • |: jq filter • t: tree • s/S: save body/with headers • esc: back")
+	if m.saveNotice != "" {
+		helpText = HelpTextStyle.Render("\n\n" + m.saveNotice)
+	}
 	return m.apiViewport.View() + helpText
 }
 
+func JSONTreeView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+	styleInput := inputStyle(m.termWidth)
+	tree := m.jsonTree
+
+	var b strings.Builder
+	b.WriteString(style1.Render(m.SelectedApi.Method + "  " + displayURL(m.SelectedApi)))
+	b.WriteString("\n")
+	b.WriteString(UrlStyle.Render(tree.current().path()))
+	b.WriteString("\n\n")
+
+	height := jsonTreeHeight(m)
+	end := min(tree.Offset+height, len(tree.Rows))
+	for i := tree.Offset; i < end; i++ {
+		line := jsonTreeLine(tree.Rows[i], m.termWidth)
+		if i == tree.Cursor {
+			b.WriteString(style4.Render("> ") + style5.Render(line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.treeVariableInput.Focused() {
+		b.WriteString("\n" + styleInput.Render(m.treeVariableInput.View()))
+	}
+	if m.hasError {
+		b.WriteString("\n" + errorStyle(m.termWidth).Render("⚠ ERROR: "+m.errorMessage+"\n\nPress 'x' to dismiss"))
+	}
+
+	helpText := HelpTextStyle.Render("\n\nj/k: move • l/h: expand/collapse • E/C: expand/collapse all • c: copy value • p: copy path • s: save as variable • t/esc: back")
+	b.WriteString(helpText)
+	return b.String()
+}
+
+func jsonTreeLine(node *jsonNode, width int) string {
+	marker := "  "
+	if node.isContainer() && node.Expanded {
+		marker = "▾ "
+	} else if node.isContainer() {
+		marker = "▸ "
+	}
+
+	label := ""
+	switch {
+	case node.Parent == nil:
+		label = "$ "
+	case node.Parent.Kind == jsonArray:
+		label = fmt.Sprintf("[%d]: ", node.Index)
+	default:
+		label = node.Key + ": "
+	}
+
+	value := node.summary()
+	switch {
+	case node.isContainer() && node.Expanded && node.Kind == jsonObject:
+		value = fmt.Sprintf("{ %d keys", len(node.Children))
+	case node.isContainer() && node.Expanded:
+		value = fmt.Sprintf("[ %d items", len(node.Children))
+	case node.Kind == jsonString:
+		value = strconv.Quote(node.Value)
+	}
+
+	prefix := strings.Repeat("  ", node.Depth) + marker + label
+	if room := width - len([]rune(prefix)) - 6; room > 0 && len([]rune(value)) > room {
+		value = string([]rune(value)[:room]) + "…"
+	}
+	switch node.Kind {
+	case jsonString:
+		value = jsonStringStyle.Render(value)
+	case jsonNumber:
+		value = jsonNumberStyle.Render(value)
+	case jsonBool:
+		value = jsonBoolStyle.Render(value)
+	case jsonNull:
+		value = jsonNullStyle.Render(value)
+	}
+	return prefix + value
+}
+
 func BuildApiPageContent(m model, termWidth int) string {
 	style1 := TitleStyle(termWidth)
 	style3 := ResponseStyle(termWidth)
@@ -157,10 +325,35 @@ func BuildApiPageContent(m model, termWidth int) string {
 	var resp strings.Builder
 
 	resp.WriteString("Status: " + statusStyle.Render(Response.Status) + "\n")
+	if Response.Proto != "" {
+		resp.WriteString("Protocol: " + Response.Proto + "\n")
+	}
 	resp.WriteString(fmt.Sprintf("Status Code: %s\n", statusStyle.Render(fmt.Sprintf("%d \n", Response.StatusCode))))
+	if attempts := describeAttempts(Response.Attempts); attempts != "" {
+		resp.WriteString("Attempts :\n" + attempts + "\n")
+	}
+	if chain := redirectChain(Response); chain != "" {
+		resp.WriteString("Redirects :\n" + chain + "\n")
+	}
 	resp.WriteString(style2.Render(" "))
 	resp.WriteString("Content Type: " + Response.ContentType + "\n")
 	resp.WriteString(fmt.Sprintf("Content Length: %d\n", Response.ContentLength))
+	resp.WriteString("Size: " + formatBytes(Response.WireSize) + " on the wire, " + formatBytes(Response.BodySize) + " decoded")
+	if Response.Decoding != "" {
+		resp.WriteString(" (" + Response.Decoding + ")")
+	}
+	resp.WriteString("\n")
+	if Response.Proxy != "" {
+		resp.WriteString("Proxy: " + Response.Proxy + "\n")
+	}
+	if Response.TLS != nil {
+		resp.WriteString(Response.TLS.describe())
+	}
+	body, truncated := shownBody(Response.Body)
+	if truncated || Response.BodyFile != "" {
+		resp.WriteString(StatusErrorStyle.Render(fmt.Sprintf("Body is %s, showing the first %s. Press 's' to save all of it.",
+			formatBytes(Response.BodySize), formatBytes(int64(len(body))))) + "\n")
+	}
 
 	resp.WriteString("\nRequestHeaders :\n")
 	for i := 0; i < len(Response.RequestHeaders); i++ {
@@ -174,8 +367,21 @@ func BuildApiPageContent(m model, termWidth int) string {
 
 	resp.WriteString("\n" + style2.Render(" "))
 
-	formattedBody := FormatJSON(Response.Body, bodyElementStyle, bodyElementStyle2)
-	resp.WriteString("\nBody:\n" + formattedBody + "\n")
+	if m.filterActive() {
+		output, truncated := shownBody(strings.Join(m.filterOutput, "\n"))
+		if truncated {
+			output += "\n" + StatusErrorStyle.Render("Output cut short, narrow the filter to see the rest.")
+		} else {
+			var results []string
+			for _, result := range m.filterOutput {
+				results = append(results, FormatJSON(result))
+			}
+			output = strings.Join(results, "\n")
+		}
+		resp.WriteString("\nBody | " + m.filterInput.Value() + ":\n" + output + "\n")
+	} else {
+		resp.WriteString("\nBody:\n" + renderBody(Response.ContentType, body, termWidth) + "\n")
+	}
 
 	b.WriteString(style1.Render("This is the Api-Page !"))
 
@@ -185,7 +391,7 @@ func BuildApiPageContent(m model, termWidth int) string {
 	} else {
 		b.WriteString(style3.Render(
 			"Selected Api is : " +
-				MethodStyle.Render(SelectedApi.Method) + " " + UrlStyle.Render(SelectedApi.Url),
+				MethodStyle.Render(SelectedApi.Method) + " " + UrlStyle.Render(displayURL(SelectedApi)),
 		))
 	}
 
@@ -202,7 +408,7 @@ func ReqPage(m model) string {
 	style3 := HomePageStyle2(m.termWidth, m.termHeight)
 	styleInput := inputStyle(m.termWidth)
 
-	name := m.SelectedApi.Method + "  " + m.SelectedApi.Url
+	name := m.SelectedApi.Method + "  " + displayURL(m.SelectedApi)
 
 	var b strings.Builder
 
@@ -222,13 +428,13 @@ func ReqPage(m model) string {
 			var line string
 
 			if m.pointer == i && m.editing {
-				line = style4.Render("> ") + style5.Render(bodyFields[i].Key+" : "+m.editingBodyFields.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(bodyFields[i].isEnabled())+bodyFields[i].Key+" : "+m.editingBodyFields.View()+"\n")
 			} else if m.pointer == i && bodyFields[i].Value == "" {
-				line = style4.Render("> ") + style5.Render(bodyFields[i].Key+" : "+m.bodyFiledValueInput.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(bodyFields[i].isEnabled())+bodyFields[i].Key+" : "+m.bodyFiledValueInput.View()+"\n")
 			} else if m.pointer == i {
-				line = style4.Render("> ") + style5.Render(bodyFields[i].Key+" : "+bodyFields[i].Value+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(bodyFields[i].isEnabled())+bodyFields[i].Key+" : "+bodyFields[i].Value+"\n")
 			} else {
-				line = style4.Render("   ") + (bodyFields[i].Key + " : " + bodyFields[i].Value + "\n")
+				line = style4.Render("   ") + (toggleBox(bodyFields[i].isEnabled()) + bodyFields[i].Key + " : " + bodyFields[i].Value + "\n")
 			}
 			items = append(items, line)
 		}
@@ -243,7 +449,7 @@ func ReqPage(m model) string {
 	}
 
 	leftBox := style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.newBodyFieldInput.View())) + "\n\n" + errorWarning
-	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter -> Open\n\n: -> Add New\n\nd -> Delete\n\nv -> Add Value\n\ne -> edit")
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter -> Open\n\n: -> Add New\n\nd -> Delete\n\nv -> Add Value\n\ne -> edit\n\nt -> Toggle")
 	layout := lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox)
 
 	b.WriteString(layout)
@@ -251,68 +457,16 @@ func ReqPage(m model) string {
 	return b.String()
 }
 
-func FormatJSON(body string, keyStyle lipgloss.Style, valueStyle lipgloss.Style) string {
-	var jsonData interface{}
-	if err := json.Unmarshal([]byte(body), &jsonData); err != nil {
-		return body
-	}
-
-	formatted, err := json.MarshalIndent(jsonData, "", "  ")
-	if err != nil {
-		return body
-	}
-
-	formattedStr := string(formatted)
-
-	keyRe := regexp.MustCompile(`"([^"]+)":`)
-	styled := keyRe.ReplaceAllStringFunc(formattedStr, func(match string) string {
-		keyMatch := keyRe.FindStringSubmatch(match)
-		if len(keyMatch) > 1 {
-			return keyStyle.Render(`"`+keyMatch[1]+`"`) + ":"
-		}
-		return match
-	})
-
-	stringValueRe := regexp.MustCompile(`:\s*"([^"]+)"`)
-	styled = stringValueRe.ReplaceAllStringFunc(styled, func(match string) string {
-		valueMatch := stringValueRe.FindStringSubmatch(match)
-		if len(valueMatch) > 1 {
-			prefix := match[:strings.Index(match, `"`)]
-			return prefix + valueStyle.Render(`"`+valueMatch[1]+`"`)
-		}
-		return match
-	})
-
-	numberRe := regexp.MustCompile(`:\s*(-?\d+\.?\d*)`)
-	styled = numberRe.ReplaceAllStringFunc(styled, func(match string) string {
-		valueMatch := numberRe.FindStringSubmatch(match)
-		if len(valueMatch) > 1 {
-			prefix := match[:strings.Index(match, valueMatch[1])]
-			return prefix + valueStyle.Render(valueMatch[1])
-		}
-		return match
-	})
-
-	boolNullRe := regexp.MustCompile(`:\s*(true|false|null)`)
-	styled = boolNullRe.ReplaceAllStringFunc(styled, func(match string) string {
-		valueMatch := boolNullRe.FindStringSubmatch(match)
-		if len(valueMatch) > 1 {
-			prefix := match[:strings.Index(match, valueMatch[1])]
-			return prefix + valueStyle.Render(valueMatch[1])
-		}
-		return match
-	})
-
-	return styled
-}
-
 func HeadersPageView(m model) string {
 	style1 := TitleStyle(m.termWidth)
 	style2 := OptionsStyle(m.termWidth)
 	style3 := HomePageStyle2(m.termWidth, m.termHeight)
 	styleInput := inputStyle(m.termWidth)
 
-	name := m.SelectedApi.Method + "  " + m.SelectedApi.Url
+	name := m.SelectedApi.Method + "  " + displayURL(m.SelectedApi)
+	if m.SelectedFolder.ID != "" {
+		name = m.SelectedFolder.Name + "/"
+	}
 
 	var b strings.Builder
 	b.WriteString(style1.Render(name))
@@ -330,13 +484,13 @@ func HeadersPageView(m model) string {
 			var line string
 
 			if m.pointer == i && m.editing {
-				line = style4.Render("> ") + style5.Render(h.Key+" "+m.editingHeader.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" "+m.editingHeader.View()+"\n")
 			} else if m.pointer == i && h.Value == "" {
-				line = style4.Render("> ") + style5.Render(h.Key+" "+m.addHeaderValue.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" "+m.addHeaderValue.View()+"\n")
 			} else if m.pointer == i {
-				line = style4.Render("> ") + style5.Render(h.Key+" "+h.Value+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" "+h.Value+"\n")
 			} else {
-				line = style4.Render("   ") + (h.Key + " " + h.Value + "\n")
+				line = style4.Render("   ") + (toggleBox(h.isEnabled()) + h.Key + " " + h.Value + "\n")
 			}
 
 			items = append(items, line)
@@ -352,7 +506,7 @@ func HeadersPageView(m model) string {
 	}
 
 	leftBox := style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.addHeaderKey.View())) + "\n\n" + errorWarning
-	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\n: -> Add New\n\nd -> Delete\n\nEnter -> Add Val\n\ne -> edit")
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\n: -> Add New\n\nd -> Delete\n\nEnter -> Add Val\n\ne -> edit\n\nt -> Toggle")
 	layout := lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox)
 
 	b.WriteString(layout)
@@ -380,13 +534,13 @@ func QueryParamsPageView(m model) string {
 		for i, h := range QueryParams {
 			var line string
 			if m.pointer == i && m.editing {
-				line = style4.Render("> ") + style5.Render(h.Key+" "+m.editingQueryParams.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" "+m.editingQueryParams.View()+"\n")
 			} else if m.pointer == i && h.Value != "" {
-				line = style4.Render("> ") + style5.Render(h.Key+" : "+h.Value+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" : "+h.Value+"\n")
 			} else if m.pointer == i {
-				line = style4.Render("> ") + style5.Render(h.Key+" : "+m.addQueryParamsValue.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" : "+m.addQueryParamsValue.View()+"\n")
 			} else {
-				line = style4.Render("   ") + h.Key + " : " + h.Value + "\n"
+				line = style4.Render("   ") + toggleBox(h.isEnabled()) + h.Key + " : " + h.Value + "\n"
 			}
 			items = append(items, line)
 		}
@@ -401,7 +555,7 @@ func QueryParamsPageView(m model) string {
 	}
 
 	leftBox := style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.addQueryParamsKey.View())) + "\n\n" + errorWarning
-	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\n: -> Add New\n\nd -> Delete\n\nEnter -> Add Val\n\ne -> edit")
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\n: -> Add New\n\nd -> Delete\n\nEnter -> Add Val\n\ne -> edit\n\nt -> Toggle")
 	layout := lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox)
 
 	b.WriteString(layout)
@@ -409,10 +563,145 @@ func QueryParamsPageView(m model) string {
 	return b.String()
 }
 
+func PathParamsPageView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+	style2 := OptionsStyle(m.termWidth)
+	style3 := HomePageStyle2(m.termWidth, m.termHeight)
+
+	var b strings.Builder
+	b.WriteString(style1.Render("Path Params: " + m.SelectedApi.Url))
+	b.WriteString("\n")
+
+	var items []string
+	if len(m.PathParams) == 0 {
+		items = append(items, "No Path Params\n\nAdd :name segments to the URL, e.g. /users/:id\n\n")
+	} else {
+		for i, p := range m.PathParams {
+			var line string
+			if m.pointer == i && m.editing {
+				line = style4.Render("> ") + style5.Render(":"+p.Key+" "+m.editingPathParam.View()+"\n")
+			} else if m.pointer == i {
+				line = style4.Render("> ") + style5.Render(":"+p.Key+" = "+p.Value+"\n")
+			} else {
+				line = style4.Render("   ") + ":" + p.Key + " = " + p.Value + "\n"
+			}
+			items = append(items, line)
+		}
+	}
+
+	var errorWarning string
+	if m.hasError {
+		errorStyle := errorStyle(m.termWidth)
+		errorWarning = errorStyle.Render("⚠ ERROR: " + m.errorMessage + "\n\nPress 'x' to dismiss")
+	}
+
+	leftBox := style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + errorWarning
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter/e -> Edit Value")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox))
+
+	return b.String()
+}
+
+func CookiesPageView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+	style2 := OptionsStyle(m.termWidth)
+	style3 := HomePageStyle2(m.termWidth, m.termHeight)
+
+	var b strings.Builder
+	b.WriteString(style1.Render("Cookies: " + m.SelectedCollection.Name))
+	b.WriteString("\n")
+
+	var items []string
+	cookies := m.SelectedCollection.Cookies
+	if len(cookies) == 0 {
+		items = append(items, "No Cookies\n\nCookies set by responses are kept here and sent with later requests\n\n")
+	}
+	for i, c := range cookies {
+		flags := ""
+		if c.Secure {
+			flags += " secure"
+		}
+		if c.HttpOnly {
+			flags += " httpOnly"
+		}
+		details := "   " + CopytextStyle().Render(c.Domain+c.Path+"  expires "+cookieExpiry(c)+flags)
+
+		var line string
+		if m.pointer == i && m.editing {
+			line = style4.Render("> ") + style5.Render(c.Name+" = "+m.editingCookie.View())
+		} else if m.pointer == i {
+			line = style4.Render("> ") + style5.Render(c.Name+" = "+c.Value)
+		} else {
+			line = "   " + c.Name + " = " + c.Value
+		}
+		items = append(items, line+"\n"+details+"\n")
+	}
+
+	var errorWarning string
+	if m.hasError {
+		errorStyle := errorStyle(m.termWidth)
+		errorWarning = errorStyle.Render("⚠ ERROR: " + m.errorMessage + "\n\nPress 'x' to dismiss")
+	}
+
+	leftBox := style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + errorWarning
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\ne -> Edit Value\n\nd -> Delete\n\nD -> Clear All")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox))
+
+	return b.String()
+}
+
+func SettingsPageView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+	style2 := OptionsStyle(m.termWidth)
+	style3 := HomePageStyle2(m.termWidth, m.termHeight)
+
+	var b strings.Builder
+	form := m.settingsForm()
+	b.WriteString(style1.Render(form.Title))
+	b.WriteString("\n")
+
+	var items []string
+	for i, row := range form.Rows {
+		var line string
+		if m.pointer == i && m.editing {
+			line = style4.Render("> ") + style5.Render(row.Label+": "+m.editingSetting.View()+"\n")
+		} else if m.pointer == i {
+			line = style4.Render("> ") + style5.Render(row.Label+": "+row.Value+"\n")
+		} else {
+			line = style4.Render("   ") + row.Label + ": " + row.Value + "\n"
+		}
+		items = append(items, line)
+	}
+
+	var errorWarning string
+	if m.hasError {
+		errorStyle := errorStyle(m.termWidth)
+		errorWarning = errorStyle.Render("⚠ ERROR: " + m.errorMessage + "\n\nPress 'x' to dismiss")
+	}
+
+	leftBox := style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + errorWarning
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nEnter/e -> Toggle/Edit")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox))
+
+	return b.String()
+}
+
 func loadingView(m model) string {
 	style1 := loadingStyle(m.termWidth, m.termHeight)
 	var b strings.Builder
-	b.WriteString(style1.Render("LOADING..."))
+
+	status := "LOADING..."
+	if m.download.received > 0 && m.download.total > 0 {
+		bar := progress.New(progress.WithDefaultGradient(), progress.WithWidth(min(m.termWidth-10, 60)))
+		percent := float64(m.download.received) / float64(m.download.total)
+		status += "\n\n" + bar.ViewAs(min(percent, 1)) +
+			"\n" + formatBytes(m.download.received) + " of " + formatBytes(m.download.total)
+	} else if m.download.received > 0 {
+		status += "\n\n" + formatBytes(m.download.received) + " received"
+	}
+	status += "\n\nesc: cancel"
+
+	b.WriteString(style1.Render(status))
 	return b.String()
 }
 
@@ -475,8 +764,13 @@ func VariablesPageView(m model) string {
 	style3 := HomePageStyle2(m.termWidth, m.termHeight)
 	styleInput := inputStyle(m.termWidth)
 
+	title := "Variables Page "
+	if m.SelectedFolder.ID != "" {
+		title = "Variables: " + m.SelectedFolder.Name + "/"
+	}
+
 	var b strings.Builder
-	b.WriteString(style2.Render("Variables Page "))
+	b.WriteString(style2.Render(title))
 	b.WriteString("\n")
 
 	var items []string
@@ -488,13 +782,13 @@ func VariablesPageView(m model) string {
 		for i, h := range m.LocalVariables {
 			var line string
 			if m.pointer == i && m.editing {
-				line = style4.Render("> ") + style5.Render(h.Key+" "+m.editingLocalVariables.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" "+m.editingLocalVariables.View()+"\n")
 			} else if m.pointer == i && h.Value != "" {
-				line = style4.Render("> ") + style5.Render(h.Key+" : "+h.Value+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" : "+h.Value+"\n")
 			} else if m.pointer == i {
-				line = style4.Render("> ") + style5.Render(h.Key+" : "+m.addVariableValue.View()+"\n")
+				line = style4.Render("> ") + style5.Render(toggleBox(h.isEnabled())+h.Key+" : "+m.addVariableValue.View()+"\n")
 			} else {
-				line = style4.Render("   ") + h.Key + " : " + h.Value + "\n"
+				line = style4.Render("   ") + toggleBox(h.isEnabled()) + h.Key + " : " + h.Value + "\n"
 			}
 			items = append(items, line)
 		}
@@ -509,10 +803,133 @@ func VariablesPageView(m model) string {
 	}
 
 	leftBox := style1.Render(lipgloss.JoinVertical(lipgloss.Left, items...)) + "\n\n" + styleInput.Render(lipgloss.JoinVertical(lipgloss.Left, m.addVariableKey.View())) + "\n\n" + errorWarning
-	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nd -> delete\n\ne -> edit")
+	rightBox := style3.Render("Commands\n----------------\nESC -> Quit\n\nk -> Up\n\nj -> Down\n\nd -> delete\n\ne -> edit\n\nt -> Toggle")
 	layout := lipgloss.JoinHorizontal(lipgloss.Top, leftBox, rightBox)
 
 	b.WriteString(layout)
 
 	return b.String()
 }
+
+func DocsPageView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+
+	title := m.SelectedCollection.Name
+	if m.docsApiID != "" {
+		title = m.SelectedApi.Method + "  " + displayURL(m.SelectedApi)
+		if m.SelectedApi.Name != "" {
+			title = m.SelectedApi.Name + "  (" + title + ")"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(style1.Render(title))
+	b.WriteString("\n")
+
+	if m.descriptionInput.Focused() {
+		b.WriteString(m.descriptionInput.View())
+		b.WriteString("\n\nctrl+s -> Save  •  esc -> Cancel")
+	} else {
+		b.WriteString(m.docsViewport.View())
+		b.WriteString("\n\ne -> Edit  •  j/k -> Scroll  •  esc -> Back")
+	}
+
+	if m.hasError {
+		errorStyle := errorStyle(m.termWidth)
+		b.WriteString("\n" + errorStyle.Render("⚠ ERROR: "+m.errorMessage+"\n\nPress 'x' to dismiss"))
+	}
+	return b.String()
+}
+
+func FinderPageView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+	style2 := OptionsStyle(m.termWidth)
+	styleInput := inputStyle(m.termWidth)
+
+	var b strings.Builder
+	b.WriteString(style1.Render("Go to Request"))
+	b.WriteString("\n")
+	b.WriteString(styleInput.Render(m.finderInput.View()))
+	b.WriteString("\n")
+
+	var items []string
+	if len(m.finderResults) == 0 {
+		items = append(items, "No matching requests")
+	}
+
+	// Keep the selected result on screen.
+	height := max(m.termHeight-12, 5)
+	start := max(m.pointer-height+1, 0)
+	for i := start; i < len(m.finderResults) && i < start+height; i++ {
+		entry := m.finderResults[i]
+		text := entry.Api.Method + " " + apiTitle(entry.Api) + "  · " + entry.Path
+		if i == m.pointer {
+			text = style4.Render("> ") + style5.Render(text)
+		} else {
+			text = "   " + text
+		}
+		items = append(items, text)
+	}
+
+	b.WriteString(style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)))
+	b.WriteString("\n↑/↓ -> Select  •  Enter -> Open  •  esc -> Close")
+	return b.String()
+}
+
+func HistoryPageView(m model) string {
+	style1 := TitleStyle(m.termWidth)
+	style2 := OptionsStyle(m.termWidth)
+	styleInput := inputStyle(m.termWidth)
+
+	var b strings.Builder
+	b.WriteString(style1.Render("Response History"))
+	b.WriteString("\n")
+	b.WriteString(styleInput.Render(m.historyInput.View()))
+	b.WriteString("\n")
+
+	var items []string
+	if m.historyError != "" {
+		items = append(items, StatusErrorStyle.Render(m.historyError))
+	} else if len(m.historyEntries) == 0 {
+		items = append(items, "No responses recorded")
+	}
+
+	// Keep the selected entry on screen.
+	height := max(m.termHeight-12, 5)
+	start := max(m.pointer-height+1, 0)
+	for i := start; i < len(m.historyEntries) && i < start+height; i++ {
+		entry := m.historyEntries[i]
+		text := entry.SentAt.Format("Jan 2 15:04:05") + "  " + entry.Status + "  " + entry.Method + " " + entry.Url
+		if i != m.pointer {
+			items = append(items, "   "+text)
+			continue
+		}
+		items = append(items, style4.Render("> ")+style5.Render(text))
+		if m.historyOpen {
+			items = append(items, historyPreview(entry, m.termWidth))
+		}
+	}
+
+	b.WriteString(style2.Render(lipgloss.JoinVertical(lipgloss.Left, items...)))
+	b.WriteString("\n↑/↓ -> Select  •  Enter -> Show/Hide Response  •  esc -> Close")
+	if m.hasError {
+		errorStyle := errorStyle(m.termWidth)
+		b.WriteString("\n" + errorStyle.Render("⚠ ERROR: "+m.errorMessage+"\n\nPress 'x' to dismiss"))
+	}
+	return b.String()
+}
+
+// historyPreview shows the headers and the start of a recorded response.
+func historyPreview(entry HistoryEntry, width int) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(entry.ResponseHeaders)) {
+		fmt.Fprintf(&b, "  %s : %s\n", key, strings.Join(entry.ResponseHeaders[key], ", "))
+	}
+	body, _ := shownBody(entry.ResponseBody)
+	lines := strings.Split(renderBody(entry.ResponseHeaders.Get("Content-Type"), body, width), "\n")
+	if len(lines) > historyPreviewLines {
+		lines = append(lines[:historyPreviewLines], CopytextStyle().Render(fmt.Sprintf("… %d more lines", len(lines)-historyPreviewLines)))
+	}
+	b.WriteString("\n" + strings.Join(lines, "\n") + "\n")
+	return b.String()
+}